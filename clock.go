@@ -0,0 +1,90 @@
+package errgroup
+
+import (
+	"context"
+	"time"
+)
+
+// A clock tells the time and makes timers for the Group's time-based features,
+// so that tests can replace the real one.
+type clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) timer
+	AfterFunc(d time.Duration, f func()) timer
+}
+
+// A timer is a *time.Timer obtained from a clock.
+type timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// realClock is the clock of the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// clock returns the group's clock, which is the real one unless a test set
+// another.
+func (g *Group) clock() clock {
+	if g.clk == nil {
+		return realClock{}
+	}
+
+	return g.clk
+}
+
+// withTimeout is like context.WithTimeout but measures d with the group's
+// clock. With a clock other than the real one, the returned Context reports no
+// deadline, but its Err is still context.DeadlineExceeded once d has elapsed.
+func (g *Group) withTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	c := g.clock()
+	if _, ok := c.(realClock); ok {
+		return context.WithTimeout(parent, d)
+	}
+
+	ctx, cancel := context.WithCancelCause(parent)
+	t := c.AfterFunc(d, func() { cancel(context.DeadlineExceeded) })
+
+	return timeoutCtx{ctx}, func() {
+		t.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+// timeoutCtx reports context.DeadlineExceeded from Err when it was canceled
+// because its clock's timer fired.
+type timeoutCtx struct {
+	context.Context
+}
+
+func (ctx timeoutCtx) Err() error {
+	err := ctx.Context.Err()
+	if err != nil && context.Cause(ctx.Context) == context.DeadlineExceeded {
+		return context.DeadlineExceeded
+	}
+
+	return err
+}
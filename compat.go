@@ -0,0 +1,24 @@
+package errgroup
+
+import "context"
+
+// Compat returns a new Group and an associated Context derived from ctx that
+// behave exactly like those returned by WithContext in
+// golang.org/x/sync/errgroup, so that code can switch imports without a change
+// in semantics:
+//
+//   - Wait returns only the first error returned by a function passed to Go,
+//     never the parent Context's error.
+//   - Go blocks on a full limit even after the Context is canceled, instead of
+//     recording the Context's error and returning.
+//   - Functions passed to Go after Wait has returned still run, and a later
+//     call to Wait waits for them.
+//
+// The extensions of this package, such as Finally and signal handling, still
+// work if configured explicitly, but none is enabled by default.
+func Compat(ctx context.Context) (*Group, context.Context) {
+	g := &Group{compat: true}
+	g.withCancel(ctx)
+
+	return g, g.ctx
+}
@@ -8,11 +8,79 @@ package errgroup
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"os"
-	"os/signal"
+	"slices"
 	"sync"
-	"syscall"
+	"sync/atomic"
+	"time"
+)
+
+type token struct{}
+
+// errSucceeded is the cause with which a Group created with WithFirstSuccess
+// cancels its Context.
+var errSucceeded = errors.New("errgroup: a function succeeded")
+
+// errFinallyPanicked is the Finally error recorded when a Finally callback
+// panics without the group recovering it.
+var errFinallyPanicked = errors.New("errgroup: a Finally callback panicked")
+
+// ErrGroupClosed is recorded, and reported by Errors, for each function passed
+// to Go or one of its variants after Wait has returned. Such functions are not
+// called.
+var ErrGroupClosed = errors.New("errgroup: Go called after Wait")
+
+// ErrCanceled is the cause with which Cancel cancels a group's Context.
+var ErrCanceled = errors.New("errgroup: canceled")
+
+// ErrFinallyTimeout is returned by Wait when the Finally callbacks do not
+// return within the timeout set by SetFinallyTimeout.
+var ErrFinallyTimeout = errors.New("errgroup: Finally timed out")
+
+// ErrErrorsDropped is joined to the error returned by Wait, when it combines
+// the recorded errors, if some were dropped because of SetMaxErrors.
+var ErrErrorsDropped = errors.New("errgroup: errors dropped")
+
+// An ErrorStrategy controls how the errors returned by functions passed to Go
+// are combined into the error returned by Wait.
+type ErrorStrategy int
+
+const (
+	// FirstError returns the first error to be returned. It is the default.
+	FirstError ErrorStrategy = iota
+
+	// LastError returns the last error to be returned.
+	LastError
+
+	// AllErrors returns every error combined with errors.Join, in the order in
+	// which they were returned.
+	AllErrors
+
+	// FirstSubmittedError returns the error of the earliest submitted function
+	// that failed, regardless of the order in which the functions returned, so
+	// that simultaneous failures are reported deterministically. Errors not
+	// returned by a function, such as those recorded for functions that were
+	// never called, are only returned if no function failed.
+	FirstSubmittedError
+)
+
+// A FinallyOrder controls whether Finally callbacks run before or after the
+// group's Context is canceled when Wait returns.
+type FinallyOrder int
+
+const (
+	// FinallyBeforeCancel runs the Finally callbacks while the Context is
+	// still live, unless a function's error already canceled it. It is the
+	// default.
+	FinallyBeforeCancel FinallyOrder = iota
+
+	// FinallyAfterCancel cancels the Context before running the Finally
+	// callbacks.
+	FinallyAfterCancel
 )
 
 // A Group is a collection of goroutines working on subtasks that are part of
@@ -20,125 +88,1865 @@ import (
 //
 // A zero Group is valid and does not cancel on error.
 type Group struct {
-	cancel       context.CancelFunc
+	parent       context.Context
+	ctx          context.Context
+	cancel       context.CancelCauseFunc
 	wg           sync.WaitGroup
+	inflight     atomic.Int64
+	idleCh       chan struct{}
+	sem          Semaphore
+	buffer       chan token
+	weighted     *weighted
+	budget       *weighted
+	rate         *limiter
+	active       atomic.Int64
+	pending      atomic.Int64
+	stats        stats
 	stop         chan struct{}
 	stopOnce     sync.Once
-	finally      func() error
+	keepStopOpen bool
+	finally      []func() error
 	finallyOnce  sync.Once
+	finallyErr   error
+	finallyOrder FinallyOrder
+	finallyLimit time.Duration
+	onSuccess    []func() error
+	onError      []func(err error) error
+	sigErr       error
+	maxLifetime  time.Duration
+	lifetime     timer
+	lifetimeOnce sync.Once
+	lifetimeErr  error
+	cancelErr    error
 	catchSignals bool
+	signals      []os.Signal
+	sigSource    <-chan os.Signal
+	forward      chan<- os.Signal
+	shutdown     time.Duration
+	exit         func(code int)
+	reraise      bool
+	noErrExit    bool
+	raise        func(sig os.Signal) error
+	sigHandler   func(sig os.Signal) bool
+	strategy     ErrorStrategy
+	ignored      []error
+	keepRunning  bool
+	keepContext  bool
+	firstSuccess bool
+	succeeded    bool
+	recoverPanic bool
+	panicHandler func(recovered any, stack []byte) error
+	up           *Group
+	propagate    bool
+	compat       bool
+	onCancel     []func(cause error)
+	onStart      []func()
+	startOnce    sync.Once
+	onTaskStart  []func()
+	onTaskFinish []func(err error, dur time.Duration)
+	onLateError  []func(err error)
+	afterCancel  []func()
+	afterDone    chan struct{}
+	afterStop    func() bool
+	lateOnce     sync.Once
+	aborted      atomic.Bool
+	reason       atomic.Pointer[Reason]
+	waitOnce     sync.Once
+	res          error
+	clean        bool
+	cause        error
+	done         chan struct{}
+	onWaited     []func()
 	errOnce      sync.Once
 	err          error
+	errStack     []byte
+	errCh        chan error
+	errChClosed  bool
+	waited       bool
+	clk          clock
+	middleware   []func(next func() error) func() error
+	running      map[int64]string
+	mu           sync.Mutex
+	errs         []error
+	errsLate     []bool
+	lastErr      error
+	maxErrors    int
+	dropped      int
+	submitted    atomic.Int64
+	lowestErr    error
+	lowestIdx    int64
 }
 
 // WithSignalHandler returns a new Group configured with a signal handler, an
 // associated Context derived from ctx, and a stop channel.
-func WithSignalHandler(ctx context.Context) (*Group, context.Context, chan struct{}) {
-	stop := make(chan struct{})
-	ctx, cancel := context.WithCancel(ctx)
-	return &Group{
-		cancel:       cancel,
-		stop:         stop,
-		catchSignals: true,
-	}, ctx, stop
+//
+// The handler catches the given signals, or SIGINT and SIGTERM if none are
+// given. SIGKILL cannot be caught, so passing it has no effect.
+//
+// When a signal is caught, the derived Context is canceled with a *SignalError
+// identifying it as the cause, which context.Cause reports.
+//
+// The stop channel is closed exactly once: when the first signal is caught,
+// after the Finally callbacks run and the Context is canceled, or otherwise
+// when the first call to Wait completes normally, so that a receiver on it is
+// always released. KeepStopOpen restricts closing it to the signal path. If
+// the caller closes the channel itself, the group does not close it again.
+func WithSignalHandler(ctx context.Context, sigs ...os.Signal) (*Group, context.Context, chan struct{}) {
+	g, ctx := New(ctx, Signals(sigs...))
+	return g, ctx, g.stop
+}
+
+// WithSignals is like WithSignalHandler but for callers that only need the
+// derived Context to be canceled on a caught signal: it returns no stop
+// channel. Wait still returns an error matching ErrSignalReceived after a
+// caught signal.
+func WithSignals(ctx context.Context, sigs ...os.Signal) (*Group, context.Context) {
+	return New(ctx, Signals(sigs...))
 }
 
 // WithContext returns a new Group and an associated Context derived from ctx.
 //
 // The derived Context is canceled the first time a function passed to Go
 // returns a non-nil error or the first time Wait returns, whichever occurs
-// first.
+// first; DisableCancelOnWait keeps Wait from canceling it after a clean run.
+// When a function's error cancels the Context, context.Cause reports that
+// error.
+// If ctx is canceled before any function returns an error, Wait returns
+// ctx.Err().
 func WithContext(ctx context.Context) (*Group, context.Context) {
-	ctx, cancel := context.WithCancel(ctx)
-	return &Group{cancel: cancel}, ctx
+	return New(ctx)
+}
+
+// WithAllErrors returns a new Group and an associated Context derived from ctx
+// that collects every non-nil error returned by functions passed to Go.
+//
+// The derived Context is canceled the same way as one returned by WithContext,
+// but Wait returns all collected errors combined with errors.Join, in the order
+// in which the functions returned them.
+func WithAllErrors(ctx context.Context) (*Group, context.Context) {
+	return New(ctx, Strategy(AllErrors))
+}
+
+// WithFirstSuccess returns a new Group and an associated Context derived from
+// ctx that stops at the first success rather than the first error.
+//
+// The derived Context is canceled the first time a function passed to Go
+// returns nil or the first time Wait returns, whichever occurs first. Errors
+// returned by the functions do not cancel it. Wait returns nil if any function
+// succeeded and otherwise all of their errors combined with errors.Join.
+func WithFirstSuccess(ctx context.Context) (*Group, context.Context) {
+	return New(ctx, FirstSuccess())
+}
+
+// WithLimit returns a new Group and an associated Context derived from ctx, as
+// WithContext does, with the number of active goroutines limited to n before
+// any function is passed to Go. Unlike SetLimit, a zero or negative n means no
+// limit.
+func WithLimit(ctx context.Context, n int) (*Group, context.Context) {
+	if n <= 0 {
+		return New(ctx)
+	}
+
+	return New(ctx, Limit(n))
 }
 
 // Finally configures the Group with a callback of sorts that returns an error
 // that propogates to the Wait method.
+//
+// Finally may be called more than once; the callbacks run in reverse order of
+// registration, like deferred calls, and their errors are combined with
+// errors.Join. A nil fn is ignored. A callback that panics does not prevent the
+// others from running.
 func (g *Group) Finally(fn func() error) {
-	g.finally = fn
+	if fn == nil {
+		return
+	}
+
+	g.mu.Lock()
+	g.finally = append(g.finally, fn)
+	g.mu.Unlock()
+}
+
+// FinallyCtx registers fn as a Finally callback that is passed a Context telling
+// why the group stopped, so that it can, say, commit on success and roll back
+// on failure. If the group did not complete cleanly, the Context is canceled
+// and context.Cause reports the error that ended it, as passed to the OnError
+// callbacks; otherwise it is not canceled. The Context carries the values of
+// the group's own but is canceled independently of it. A nil fn is ignored.
+func (g *Group) FinallyCtx(fn func(ctx context.Context) error) {
+	if fn == nil {
+		return
+	}
+
+	g.Finally(func() error {
+		parent := context.Background()
+		if g.ctx != nil {
+			parent = context.WithoutCancel(g.ctx)
+		}
+		ctx, cancel := context.WithCancelCause(parent)
+		defer cancel(nil)
+
+		g.mu.Lock()
+		cause := g.outcome()
+		g.mu.Unlock()
+
+		if cause != nil {
+			cancel(cause)
+		}
+
+		return fn(ctx)
+	})
+}
+
+// OnSuccess registers fn to be called when the Finally callbacks run, only if
+// the group completed cleanly: no function passed to Go returned an error, no
+// signal was caught, the maximum lifetime did not elapse, Cancel did not cancel
+// the group, and the parent Context was not canceled. It suits cleanup that
+// only makes sense on success, such as committing a transaction. Its error
+// propagates to Wait as a Finally error does. OnSuccess and OnError callbacks
+// run before the Finally callbacks, in order of registration. A nil fn is
+// ignored.
+func (g *Group) OnSuccess(fn func() error) {
+	if fn == nil {
+		return
+	}
+
+	g.mu.Lock()
+	g.onSuccess = append(g.onSuccess, fn)
+	g.mu.Unlock()
+}
+
+// OnError registers fn to be called with the error that ended the group when
+// the Finally callbacks run, only if the group did not complete cleanly; see
+// OnSuccess. The error is the first one returned by a function passed to Go,
+// or else the caught signal's *SignalError, ErrMaxLifetimeExceeded,
+// ErrCanceled, or the parent Context's error. The error fn returns propagates
+// to Wait as a Finally error does. A nil fn is ignored.
+func (g *Group) OnError(fn func(err error) error) {
+	if fn == nil {
+		return
+	}
+
+	g.mu.Lock()
+	g.onError = append(g.onError, fn)
+	g.mu.Unlock()
+}
+
+// SetFinallyOrder configures whether the Finally callbacks run before or after
+// Wait cancels the group's Context.
+func (g *Group) SetFinallyOrder(o FinallyOrder) {
+	g.finallyOrder = o
+}
+
+// SetFinallyTimeout bounds how long Wait waits for the Finally callbacks to
+// return. The callbacks then run in their own goroutine, and if they are still
+// running d after they start, Wait stops waiting for them and returns an error
+// matching ErrFinallyTimeout in place of their errors. A zero or negative d,
+// the default, waits for them indefinitely.
+//
+// The goroutine is not stopped on timeout: a callback that ignores the timeout
+// and never returns leaks it.
+func (g *Group) SetFinallyTimeout(d time.Duration) {
+	g.finallyLimit = d
+}
+
+// OnCancel registers fn to be called once, with the cause, when the group is
+// canceled by a function passed to Go returning an error, by a caught signal,
+// by the lifetime set by SetMaxLifetime running out, by a function returning
+// nil in a Group created with WithFirstSuccess, or by Cancel. It is not called
+// when the Context is canceled only because Wait returned or its parent was
+// canceled, nor for a Group without a Context. OnCancel may be called more
+// than once; the callbacks run in order of registration. A nil fn is ignored.
+func (g *Group) OnCancel(fn func(cause error)) {
+	if fn == nil {
+		return
+	}
+
+	g.mu.Lock()
+	g.onCancel = append(g.onCancel, fn)
+	g.mu.Unlock()
+}
+
+// OnStart registers fn to be called once, just before the first function
+// passed to Go is called, which suits lazily initializing resources shared by
+// the functions. Functions starting concurrently wait for the OnStart callbacks
+// to return. If no function is ever called, neither is fn. OnStart may be
+// called more than once; the callbacks run in order of registration. A nil fn
+// is ignored.
+func (g *Group) OnStart(fn func()) {
+	if fn == nil {
+		return
+	}
+
+	g.mu.Lock()
+	g.onStart = append(g.onStart, fn)
+	g.mu.Unlock()
+}
+
+// SetGoMiddleware adds mw to the middleware that wraps every function passed to
+// Go, say to start and end a tracing span around it in the function's
+// goroutine. mw is given the function, or the middleware added after it, as
+// next and returns the function to call in its place, which must call next and
+// should return its error. Middleware applies in the order it was added, the
+// first being outermost, and panics in it are handled like those in the
+// function. A nil mw is ignored.
+func (g *Group) SetGoMiddleware(mw func(next func() error) func() error) {
+	if mw == nil {
+		return
+	}
+
+	g.mu.Lock()
+	g.middleware = append(g.middleware, mw)
+	g.mu.Unlock()
+}
+
+// AfterCancel registers fn to be called once, in its own goroutine, right after
+// the group's Context is canceled by any cause other than Wait returning: an
+// error, a signal, the maximum lifetime, Cancel, or the parent Context. It
+// suits best-effort work such as flushing telemetry, kept apart from the
+// resource cleanup done by Finally. Running functions are not held up by it,
+// but Wait does not return until it has. It is not called for a group that
+// completes without being canceled, nor for a Group without a Context.
+// AfterCancel may be called more than once; the callbacks run in order of
+// registration. A nil fn is ignored.
+func (g *Group) AfterCancel(fn func()) {
+	if fn == nil || g.ctx == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.afterCancel = append(g.afterCancel, fn)
+	if g.afterDone == nil {
+		g.watchCancel()
+	}
+}
+
+// watchCancel arranges for the AfterCancel callbacks to run once the group's
+// Context is canceled, and for g.afterDone to be closed once they have. g.mu
+// must be held.
+func (g *Group) watchCancel() {
+	done := make(chan struct{})
+	g.afterDone = done
+	g.afterStop = context.AfterFunc(g.ctx, func() {
+		defer close(done)
+
+		if g.CancelReason().Kind == ReasonCompleted {
+			return
+		}
+
+		g.mu.Lock()
+		afterCancel := g.afterCancel
+		g.mu.Unlock()
+
+		for _, fn := range afterCancel {
+			fn()
+		}
+	})
+}
+
+// OnTaskStart registers fn to be called in each goroutine started by the group
+// just before the function passed to Go is called. OnTaskStart may be called
+// more than once; the callbacks run in order of registration. A nil fn is
+// ignored.
+func (g *Group) OnTaskStart(fn func()) {
+	if fn == nil {
+		return
+	}
+
+	g.mu.Lock()
+	g.onTaskStart = append(g.onTaskStart, fn)
+	g.mu.Unlock()
+}
+
+// OnTaskFinish registers fn to be called in each goroutine started by the
+// group after the function passed to Go returns, with the error it returned
+// and how long it ran. If the group recovers panics, a panicking function is
+// reported with its *PanicError. OnTaskFinish may be called more than once;
+// the callbacks run in order of registration. A nil fn is ignored.
+func (g *Group) OnTaskFinish(fn func(err error, dur time.Duration)) {
+	if fn == nil {
+		return
+	}
+
+	g.mu.Lock()
+	g.onTaskFinish = append(g.onTaskFinish, fn)
+	g.mu.Unlock()
 }
 
 // Wait blocks until all function calls from the Go method have returned, then
-// returns the first non-nil error (if any) from them.
+// returns the first non-nil error (if any) from them, or the errors selected by
+// the group's ErrorStrategy.
+//
+// If one of the signals the Group was configured with is caught, run finally,
+// cancel the context, and close the stop channel. Wait then also returns a
+// *SignalError matching ErrSignalReceived, joined with the task error and the
+// Finally errors if any, so that each remains inspectable with errors.Is and
+// errors.As.
 //
-// If SIGINT, SIGKILL, or SIGTERM is caught, run finally, cancel the context,
-// and close the stop channel.
+// Only the first call to Wait catches signals, runs Finally, and cancels the
+// context. Later calls return the error the first call returned, even if the
+// parent Context is canceled in between; functions passed to Go after Wait has
+// returned are not called. If a Finally callback panics and the group does not
+// recover panics, the first call to Wait panics once it has finished its work,
+// and later calls return the error it would have, joined with one telling of
+// the panic.
 func (g *Group) Wait() error {
-	if g.catchSignals {
-		c := make(chan os.Signal, 2)
-		signal.Notify(c, os.Interrupt, os.Kill, syscall.SIGTERM)
+	g.waitOnce.Do(g.wait)
+	g.wg.Wait()
 
-		go func() {
-			<-c
+	return g.waitResult()
+}
 
-			g.runFinally()
+// WaitAll waits as Wait does and returns both the first error recorded by the
+// group, as the FirstError strategy would, and every recorded error combined
+// with errors.Join, as Errors reports them, regardless of the group's
+// ErrorStrategy. Errors from signals, the maximum lifetime, and Finally are not
+// included; Wait reports them.
+func (g *Group) WaitAll() (first, all error) {
+	g.Wait()
 
-			if g.cancel != nil {
-				g.cancel()
-			}
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-			g.closeStop()
+	return g.err, errors.Join(g.errs...)
+}
 
-			<-c
-			os.Exit(0)
-		}()
+func (g *Group) wait() {
+	var stopSignals func()
+	if g.catchSignals {
+		stopSignals = g.handleSignals()
 	}
 
 	g.wg.Wait()
 
+	g.stopLifetime()
+
+	if stopSignals != nil {
+		stopSignals()
+	}
+
+	if g.finallyOrder == FinallyAfterCancel {
+		g.cancelOnWait()
+	}
+
+	// Finish even if a Finally callback panics without the group recovering
+	// it, so that the panic reaches the caller of Wait with the group already
+	// waited for: later calls to Wait return its result and Done is closed.
+	panicked := true
+	defer func() {
+		if panicked {
+			g.mu.Lock()
+			g.finallyErr = errFinallyPanicked
+			g.mu.Unlock()
+		}
+		g.finishWait()
+	}()
+
 	g.runFinally()
+	panicked = false
+}
+
+// finishWait does the work of wait that follows the Finally callbacks.
+func (g *Group) finishWait() {
+	g.cancelOnWait()
+
+	g.mu.Lock()
+	afterDone := g.afterDone
+	g.mu.Unlock()
+
+	if afterDone != nil && g.canceled() && g.CancelReason().Kind != ReasonCompleted {
+		<-afterDone
+	}
+
+	if !g.keepStopOpen {
+		g.closeStop()
+	}
+
+	if g.stats.begin.Load() != nil {
+		end := g.clock().Now()
+		g.stats.end.Store(&end)
+	}
+
+	res := g.result()
+
+	g.mu.Lock()
+	g.res = res
+	g.clean = g.isClean()
+	g.cause = g.outcome()
+	g.waited = true
+	g.closeErrChan()
+	if g.done != nil {
+		close(g.done)
+	}
+	onWaited := g.onWaited
+	g.onWaited = nil
+	g.mu.Unlock()
+
+	for _, fn := range onWaited {
+		fn()
+	}
+}
+
+// afterWait arranges for fn to be called once the current round's wait has
+// completed, or calls it at once if it already has. It lets types embedding a
+// Group, such as ResultGroup, finish their own work however the group is
+// waited for: by Wait, Close, WaitContext, or WaitAll.
+func (g *Group) afterWait(fn func()) {
+	g.mu.Lock()
+	if !g.waited {
+		g.onWaited = append(g.onWaited, fn)
+		g.mu.Unlock()
+		return
+	}
+	g.mu.Unlock()
+
+	fn()
+}
+
+// waitResult returns the error computed when the first call to Wait completed,
+// so that it does not change if, say, the parent Context is canceled later. In
+// compat mode, where functions passed to Go after Wait still run, it is
+// computed afresh.
+func (g *Group) waitResult() error {
+	if g.compat {
+		return g.result()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.res
+}
+
+// cancelOnWait cancels the group's Context as Wait returns, unless the group
+// completed cleanly and DisableCancelOnWait was called.
+func (g *Group) cancelOnWait() {
+	if g.keepContext && !g.failed() {
+		return
+	}
+
+	g.release()
+}
+
+// release cancels the group's Context as Wait does, with ReasonCompleted as
+// its reason, so that neither the OnCancel nor the AfterCancel callbacks run.
+func (g *Group) release() {
+	if g.cancel == nil {
+		return
+	}
+
+	g.setReason(nil)
+	g.cancel(nil)
+}
+
+// ErrChan returns a channel that receives the first error returned by a
+// function passed to Go as soon as it is recorded, and is then closed. If the
+// first call to Wait completes without an error, the channel is closed without
+// receiving a value. Successive calls return the same channel.
+func (g *Group) ErrChan() <-chan error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.errCh == nil {
+		g.errCh = make(chan error, 1)
+		g.closeErrChan()
+	}
+
+	return g.errCh
+}
+
+// closeErrChan delivers the first error, if any, on the channel returned by
+// ErrChan and closes it, once there is an error or Wait is done. g.mu must be
+// held.
+func (g *Group) closeErrChan() {
+	if g.errCh == nil || g.errChClosed {
+		return
+	}
+
+	if g.err != nil {
+		g.errCh <- g.err
+	} else if !g.waited {
+		return
+	}
+
+	close(g.errCh)
+	g.errChClosed = true
+}
+
+// WaitContext is like Wait but returns ctx.Err() if ctx is done before all
+// function calls from the Go method have returned.
+//
+// Returning early does not cancel the group: its goroutines keep running and
+// are leaked unless something else makes them return, such as canceling the
+// group's Context. Nor does it do any of Wait's work: Finally does not run and
+// functions may still be passed to Go. Wait may be called afterwards to wait
+// for them. Errors they return after WaitContext has returned are reported to
+// the OnLateError callbacks. Since Wait is only called once the functions have
+// returned, signals are not caught while WaitContext waits for them.
+func (g *Group) WaitContext(ctx context.Context) error {
+	select {
+	case <-g.idle():
+		return g.Wait()
+	case <-ctx.Done():
+		g.watchLate()
+		return ctx.Err()
+	}
+}
+
+// track counts a goroutine of the group, or a function waiting for one, that
+// Wait must wait for.
+func (g *Group) track() {
+	g.wg.Add(1)
+	g.inflight.Add(1)
+}
 
-	if g.cancel != nil {
-		g.cancel()
+// untrack undoes track, closing the channel returned by idle once nothing is
+// left in flight.
+func (g *Group) untrack() {
+	if g.inflight.Add(-1) == 0 {
+		g.mu.Lock()
+		if g.idleCh != nil && g.inflight.Load() == 0 {
+			close(g.idleCh)
+			g.idleCh = nil
+		}
+		g.mu.Unlock()
+	}
+
+	g.wg.Done()
+}
+
+// idle returns a channel closed once all function calls from the Go method have
+// returned, without doing any of Wait's other work. Unlike a goroutine calling
+// g.wg.Wait, it lets functions still be passed to Go afterwards.
+func (g *Group) idle() <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.inflight.Load() == 0 {
+		idle := make(chan struct{})
+		close(idle)
+		return idle
+	}
+
+	if g.idleCh == nil {
+		g.idleCh = make(chan struct{})
 	}
 
-	g.closeStop()
+	return g.idleCh
+}
+
+// OnLateError registers fn to be called, from a watchdog goroutine, with each
+// error recorded after a call to WaitContext returned early, once all the
+// abandoned goroutines have returned, so that their errors are not silently
+// lost. fn is not called if they all succeed, nor if WaitContext never returns
+// early. OnLateError may be called more than once; the callbacks run in order
+// of registration. A nil fn is ignored.
+func (g *Group) OnLateError(fn func(err error)) {
+	if fn == nil {
+		return
+	}
+
+	g.mu.Lock()
+	g.onLateError = append(g.onLateError, fn)
+	g.mu.Unlock()
+}
+
+// watchLate starts, at most once, a goroutine that waits for the group and
+// reports the errors recorded from now on to the OnLateError callbacks.
+func (g *Group) watchLate() {
+	g.lateOnce.Do(func() {
+		g.mu.Lock()
+		onLateError, n := g.onLateError, len(g.errs)
+		g.mu.Unlock()
+
+		if len(onLateError) == 0 {
+			return
+		}
+
+		go func() {
+			<-g.idle()
+
+			g.mu.Lock()
+			var late []error
+			if n <= len(g.errs) { // Reset may have cleared them.
+				late = append(late, g.errs[n:]...)
+			}
+			g.mu.Unlock()
+
+			for _, err := range late {
+				for _, fn := range onLateError {
+					fn(err)
+				}
+			}
+		}()
+	})
+}
+
+// Done returns a channel that is closed once the first call to Wait has
+// completed, with all function calls from the Go method returned and Wait's
+// cleanup, including Finally, run, which suits selecting on the group's end
+// alongside other events. Done does not itself wait, so Wait must be called,
+// typically in another goroutine; its result remains available by calling it
+// again. Successive calls to Done return the same channel.
+func (g *Group) Done() <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.done == nil {
+		g.done = make(chan struct{})
+		if g.waited {
+			close(g.done)
+		}
+	}
 
-	return g.err
+	return g.done
 }
 
 // Go calls the given function in a new goroutine.
 //
 // The first call to return a non-nil error cancels the group; its error will be
 // returned by Wait.
+//
+// If the group has a limit set, Go blocks until the new goroutine can be added
+// without the number of active goroutines in the group exceeding the limit. If
+// the group's Context is canceled while Go is blocked, Go returns without
+// calling the function and records the Context's error.
+//
+// If the group also has a buffer set, Go returns without blocking while there
+// is room in the buffer; see SetBuffer.
+//
+// Once Wait has returned, Go does not call the function, since its error could
+// never be observed; it records ErrGroupClosed, reported by Errors, instead.
+// Reset makes the group usable again.
+//
+// Go, like each of its variants, panics if f is nil, before starting anything.
 func (g *Group) Go(f func() error) {
-	g.wg.Add(1)
+	if f == nil {
+		panicNilFunc("Go")
+	}
+
+	g.submit("", f, f, nil, nil)
+}
+
+// goWrapped calls f in a new goroutine as Go does. f wraps src, the function
+// passed to one of Go's variants, which is the function FirstErrorStack names.
+func (g *Group) goWrapped(src any, f func() error) {
+	g.submit("", src, f, nil, nil)
+}
+
+// GoNamed calls the given function in a new goroutine, as Go does, labeling it
+// with name. A non-nil error returned by the function is wrapped with the name
+// before it is recorded.
+func (g *Group) GoNamed(name string, f func() error) {
+	if f == nil {
+		panicNilFunc("GoNamed")
+	}
+
+	g.submit(name, f, f, nil, nil)
+}
+
+// GoCtx calls the given function in a new goroutine, passing it the Context
+// associated with the group. For a Group not created with a Context, the
+// function receives context.Background().
+//
+// GoCtx otherwise behaves like Go.
+func (g *Group) GoCtx(f func(ctx context.Context) error) {
+	if f == nil {
+		panicNilFunc("GoCtx")
+	}
+
+	ctx := g.context()
+	g.goWrapped(f, func() error { return f(ctx) })
+}
+
+// GoTimeout calls the given function in a new goroutine, as GoCtx does, but
+// passes it a Context derived from the group's that is canceled d after the
+// function starts, or when it returns.
+func (g *Group) GoTimeout(d time.Duration, f func(ctx context.Context) error) {
+	if f == nil {
+		panicNilFunc("GoTimeout")
+	}
+
+	parent := g.context()
+	g.goWrapped(f, func() error {
+		ctx, cancel := g.withTimeout(parent, d)
+		defer cancel()
+
+		return f(ctx)
+	})
+}
+
+// GoCancelable calls the given function in a new goroutine, as GoCtx does, but
+// passes it a Context derived from the group's that is also canceled by the
+// returned function, which cancels only this function's Context. The returned
+// function may be called any number of times, including after the function
+// has returned.
+func (g *Group) GoCancelable(f func(ctx context.Context) error) context.CancelFunc {
+	if f == nil {
+		panicNilFunc("GoCancelable")
+	}
+
+	ctx, cancel := context.WithCancel(g.context())
+	g.goWrapped(f, func() error {
+		defer cancel()
+		return f(ctx)
+	})
+
+	return cancel
+}
+
+// GoWithValues calls the given function in a new goroutine, as GoCtx does, but
+// passes it a Context derived from the group's that also carries the values in
+// kv, keyed as in the map. The values are read when GoWithValues is called and
+// are visible only to this function. Keys must be comparable and, as for
+// context.WithValue, should not be of a built-in type.
+func (g *Group) GoWithValues(kv map[any]any, f func(ctx context.Context) error) {
+	if f == nil {
+		panicNilFunc("GoWithValues")
+	}
+
+	ctx := g.context()
+	for k, v := range kv {
+		ctx = context.WithValue(ctx, k, v)
+	}
+
+	g.goWrapped(f, func() error { return f(ctx) })
+}
+
+// GoAfter calls the given function in a new goroutine, as Go does, once ready
+// is closed or receives a value. GoAfter itself does not block: the function
+// waits for ready without holding a slot under the group's limit, and Wait
+// waits for it. If the group's Context is done first, the function is never
+// called.
+func (g *Group) GoAfter(ready <-chan struct{}, f func() error) {
+	if f == nil {
+		panicNilFunc("GoAfter")
+	}
+
+	done := g.context().Done()
 
+	g.track()
 	go func() {
-		defer g.wg.Done()
+		defer g.untrack()
 
-		if err := f(); err != nil {
-			g.errOnce.Do(func() {
-				g.err = err
-				if g.cancel != nil {
-					g.cancel()
-				}
-			})
+		select {
+		case <-ready:
+		case <-done:
+			return
+		}
+
+		// Both cases may have been ready at once.
+		select {
+		case <-done:
+		default:
+			g.submit("", f, f, nil, nil)
 		}
 	}()
 }
 
-func (g *Group) closeStop() {
-	g.stopOnce.Do(func() {
-		if g.stop != nil {
-			close(g.stop)
+// TryGo calls the given function in a new goroutine only if the number of
+// active goroutines in the group is currently below the configured limit.
+//
+// The return value reports whether the goroutine was started. Like Go, TryGo
+// does not start it once Wait has returned.
+func (g *Group) TryGo(f func() error) bool {
+	if f == nil {
+		panicNilFunc("TryGo")
+	}
+
+	if g.closed() {
+		return false
+	}
+
+	if g.sem != nil && !g.sem.TryAcquire(1) {
+		return false
+	}
+
+	if g.rate != nil && !g.rate.allow(g.clock()) {
+		if g.sem != nil {
+			g.sem.Release(1)
 		}
-	})
+		return false
+	}
+
+	g.start(g.nextIndex(), "", f, f, nil)
+
+	return true
 }
 
-func (g *Group) runFinally() {
-	g.finallyOnce.Do(func() {
-		if g.finally == nil {
-			g.finally = func() error { return nil }
+// SetLimit limits the number of active goroutines in this group to at most n. A
+// negative value indicates no limit. A limit of zero will prevent any new
+// goroutines from being added.
+//
+// Any subsequent call to the Go method will block until it can add an active
+// goroutine without exceeding the configured limit.
+//
+// The limit must not be modified while any goroutines in the group are active;
+// use Resize to change it while they are.
+func (g *Group) SetLimit(n int) {
+	if _, ok := g.sem.(*weighted); !ok && g.sem != nil {
+		// The active goroutines would release their units of a semaphore
+		// given to SetSemaphore into the new one.
+		if active := g.Active(); active != 0 {
+			panic(fmt.Errorf("errgroup: replace semaphore while %v goroutines in the group are still active", active))
 		}
+	}
 
-		if err := g.finally(); err != nil {
-			if g.err == nil {
-				g.err = err
-			} else {
-				g.err = fmt.Errorf("%s: %w", g.err, err) // not sure if I should do this
-			}
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+
+	if s, ok := g.sem.(*weighted); ok {
+		if held := s.Held(); held != 0 {
+			panic(fmt.Errorf("errgroup: modify limit while %v goroutines in the group are still active", held))
 		}
-	})
+	}
+
+	g.sem = newWeighted(int64(n))
+}
+
+// Resize changes the limit set by SetLimit while goroutines in the group may be
+// active and concurrently with calls to Go. Growing the limit lets blocked
+// calls to Go proceed at once. Shrinking it does not stop active goroutines,
+// but no new ones start until the number active is below n. A negative n lifts
+// the limit in the same way. For a group without a limit, or one limited by a
+// semaphore given to SetSemaphore, Resize behaves like SetLimit, replacing the
+// semaphore, and panics if any goroutines are active.
+func (g *Group) Resize(n int) {
+	s, ok := g.sem.(*weighted)
+	if !ok {
+		g.SetLimit(n)
+		return
+	}
+
+	if n < 0 {
+		s.Resize(math.MaxInt64)
+		return
+	}
+
+	s.Resize(int64(n))
+}
+
+// A Semaphore bounds how many functions passed to Go run at once, each holding
+// one unit from when it starts until it returns. *semaphore.Weighted from
+// golang.org/x/sync/semaphore satisfies it.
+type Semaphore interface {
+	// Acquire blocks until n units are available or ctx is done, returning
+	// ctx.Err() in the latter case.
+	Acquire(ctx context.Context, n int64) error
+
+	// TryAcquire acquires n units without blocking and reports whether it did.
+	TryAcquire(n int64) bool
+
+	// Release releases n units.
+	Release(n int64)
+}
+
+// SetSemaphore makes the group take a unit of sem for each function passed to
+// Go, in place of the limit set by SetLimit, so that several groups sharing
+// sem also share its bound on the functions running at once. A nil sem removes
+// the limit. SetLimit and Resize replace sem in turn; they panic if any
+// goroutines in the group, which hold units of sem, are still active.
+//
+// The semaphore must not be modified while any goroutines in the group are
+// active.
+func (g *Group) SetSemaphore(sem Semaphore) {
+	g.sem = sem
+}
+
+// SubGroup returns a new Group, configured by opts, and an associated Context
+// derived from the group's, so that canceling the group also cancels the
+// subgroup. With the PropagateErrors option, errors returned by functions
+// passed to the subgroup's Go are recorded by the group too, canceling it.
+//
+// The group's Wait does not wait for the subgroup's goroutines; a common
+// pattern is to call the subgroup's Wait from a function passed to the
+// group's Go.
+func (g *Group) SubGroup(opts ...Option) (*Group, context.Context) {
+	child, ctx := New(g.context(), opts...)
+	child.up = g
+
+	return child, ctx
+}
+
+// Reset prepares the group for reuse after Wait has returned. It clears the
+// recorded errors and, for a Group with a Context, derives a fresh Context
+// from the original parent, which is passed to functions given to GoCtx. The
+// configuration, including the limit and Finally callbacks, is kept, but a
+// stop channel closed by a previous round stays closed.
+//
+// Reset panics if any goroutines in the group are still active, or if any
+// functions passed to Go are still waiting to start: blocked on the limit, in
+// the buffer set by SetBuffer, or on the ready channel given to GoAfter.
+func (g *Group) Reset() {
+	if n := g.Active(); n != 0 {
+		panic(fmt.Errorf("errgroup: reset while %v goroutines in the group are still active", n))
+	}
+	if g.inflight.Load() != 0 || g.Pending() != 0 {
+		panic(errors.New("errgroup: reset while functions passed to Go are still waiting to start"))
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.errOnce = sync.Once{}
+	g.err = nil
+	g.errStack = nil
+	g.errs = nil
+	g.errsLate = nil
+	g.lastErr = nil
+	g.dropped = 0
+	g.submitted.Store(0)
+	g.lowestErr = nil
+	g.finallyOnce = sync.Once{}
+	g.finallyErr = nil
+	g.sigErr = nil
+	g.lifetime = nil
+	g.lifetimeOnce = sync.Once{}
+	g.lifetimeErr = nil
+	g.cancelErr = nil
+	g.succeeded = false
+	keepTasks := g.stats.keepTasks.Load()
+	g.stats = stats{}
+	g.stats.keepTasks.Store(keepTasks)
+	g.aborted.Store(false)
+	g.reason.Store(nil)
+	g.startOnce = sync.Once{}
+	g.lateOnce = sync.Once{}
+	g.waitOnce = sync.Once{}
+	g.waited = false
+	g.res = nil
+	g.clean = false
+	g.cause = nil
+	g.errCh = nil
+	g.errChClosed = false
+	g.done = nil
+
+	if g.afterStop != nil {
+		g.afterStop()
+		g.afterStop, g.afterDone = nil, nil
+	}
+
+	if g.parent != nil {
+		g.cancel(nil)
+		g.ctx, g.cancel = context.WithCancelCause(g.parent)
+
+		if len(g.afterCancel) > 0 {
+			g.watchCancel()
+		}
+	}
+}
+
+// SetErrorStrategy configures how Wait combines the errors returned by
+// functions passed to Go. Regardless of the strategy, the first error cancels
+// the group.
+func (g *Group) SetErrorStrategy(s ErrorStrategy) {
+	g.mu.Lock()
+	g.strategy = s
+	g.mu.Unlock()
+}
+
+// EnableCancel gives a Group not created with a Context an internal one, derived
+// from context.Background, so that it cancels on error like a Group returned by
+// WithContext: functions passed to GoCtx observe the cancellation and OnCancel
+// callbacks run. It does nothing for a Group that already has a Context.
+func (g *Group) EnableCancel() {
+	if g.cancel == nil {
+		g.withCancel(context.Background())
+	}
+}
+
+// SetIgnoredErrors leaves errors matching any of targets, as reported by
+// errors.Is, out of the error Wait returns under the AllErrors strategy. This
+// is typically used with context.Canceled, and perhaps context.DeadlineExceeded,
+// to drop the errors of functions that merely observed the group's
+// cancellation. Errors still returns every error.
+func (g *Group) SetIgnoredErrors(targets ...error) {
+	g.mu.Lock()
+	g.ignored = targets
+	g.mu.Unlock()
+}
+
+// SetMaxErrors bounds to n the number of errors the group retains, so that
+// memory stays bounded when a great many functions fail. Errors beyond the
+// first n are still counted, as reported by DroppedErrors, but are neither
+// returned by Errors nor reported to the OnLateError callbacks. When Wait
+// joins the recorded errors, as under the AllErrors strategy, it appends an
+// error matching ErrErrorsDropped that tells how many were dropped. A zero or
+// negative n, the default, means no bound.
+//
+// SetMaxErrors must be called before the first function is passed to Go.
+func (g *Group) SetMaxErrors(n int) {
+	g.mu.Lock()
+	g.maxErrors = n
+	g.mu.Unlock()
+}
+
+// DroppedErrors returns the number of errors that were not retained because
+// of SetMaxErrors.
+func (g *Group) DroppedErrors() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.dropped
+}
+
+// DisableCancelOnError keeps the group's Context from being canceled when a
+// function passed to Go returns an error, so that the remaining functions run
+// to completion. Errors are still recorded and returned by Wait.
+func (g *Group) DisableCancelOnError() {
+	g.keepRunning = true
+}
+
+// DisableCancelOnWait keeps Wait from canceling the group's Context when the
+// group completes cleanly, so that the Context remains usable for work after
+// Wait. The Context is still canceled on error, on a caught signal, or by its
+// parent. Call Cancel once done with it to release its resources; after Wait,
+// Cancel does so as Wait would have, without running the OnCancel or
+// AfterCancel callbacks, and CancelReason reports ReasonCompleted.
+func (g *Group) DisableCancelOnWait() {
+	g.keepContext = true
+}
+
+// Errors returns a copy of every non-nil error returned by functions passed to
+// Go, in the order in which they were returned, regardless of the group's
+// ErrorStrategy. It is meant to be called after Wait.
+func (g *Group) Errors() []error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.errs) == 0 {
+		return nil
+	}
+
+	return append([]error(nil), g.errs...)
+}
+
+// Succeeded reports whether the group finished cleanly: Wait has returned, no
+// error was recorded, no Finally callback failed, no signal was caught, the
+// maximum lifetime did not elapse, Cancel did not cancel the group, and the
+// parent Context was not canceled. In a Group created with WithFirstSuccess,
+// errors recorded by functions that failed are ignored once one succeeds, as
+// they are by Wait. It reports false until Wait has returned.
+func (g *Group) Succeeded() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.waited && g.clean
+}
+
+// isClean reports whether the group has finished cleanly so far, as Succeeded
+// reports once Wait has returned. g.mu must be held.
+func (g *Group) isClean() bool {
+	won := g.firstSuccess && g.succeeded
+	switch {
+	case len(g.errs) > 0 && !won:
+		return false
+	case g.finallyErr != nil, g.sigErr != nil, g.lifetimeErr != nil, g.cancelErr != nil:
+		return false
+	case g.parent != nil && g.parent.Err() != nil && !g.succeeded:
+		return false
+	}
+
+	return true
+}
+
+// A RecordedError is an error recorded by a Group, as reported by Errors, along
+// with whether the group's Context had already been canceled when it was
+// recorded, which distinguishes independent failures from errors likely caused
+// by the cancellation.
+type RecordedError struct {
+	Err         error
+	AfterCancel bool
+}
+
+// RecordedErrors returns the errors Errors would, in the same order, each
+// tagged with whether it was recorded after the group's Context was canceled.
+// For a Group without a Context, no error is recorded after cancellation.
+func (g *Group) RecordedErrors() []RecordedError {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.errs) == 0 {
+		return nil
+	}
+
+	recorded := make([]RecordedError, len(g.errs))
+	for i, err := range g.errs {
+		recorded[i] = RecordedError{Err: err, AfterCancel: g.errsLate[i]}
+	}
+
+	return recorded
+}
+
+// canceled reports whether the group's Context has been canceled.
+func (g *Group) canceled() bool {
+	return g.ctx != nil && g.ctx.Err() != nil
+}
+
+// SetBuffer lets up to n functions passed to Go wait for a free slot under the
+// group's limit without blocking the caller; Go only blocks once the buffer is
+// full. Buffered functions start in no particular order. If the group's
+// Context is canceled first, they are dropped without running and the
+// Context's error is recorded, as for a call to Go blocked on the limit. A
+// zero or negative n, the default, disables buffering. SetBuffer has no
+// effect on a group without a limit.
+//
+// The buffer must not be modified while any functions are waiting in it.
+func (g *Group) SetBuffer(n int) {
+	if n <= 0 {
+		g.buffer = nil
+		return
+	}
+
+	g.buffer = make(chan token, n)
+}
+
+// FirstErrorStack returns the stack trace of the goroutine whose function
+// returned the group's first error, preceded by a line naming the function
+// passed to Go, or to the variant of Go used. The trace is taken after the
+// function returned, so it shows the group's goroutine recording the error,
+// not the point in the function where the error arose. It returns nil if no
+// function has returned an error.
+func (g *Group) FirstErrorStack() []byte {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.errStack
+}
+
+// Active returns the number of goroutines started by the group that have not
+// yet returned.
+func (g *Group) Active() int {
+	return int(g.active.Load())
+}
+
+// RunningTasks returns the names of the functions passed to GoNamed that have
+// started but not yet returned, in the order in which they were submitted. It
+// suits logging the work still stuck when the shutdown timeout forces the
+// program to exit, say from the function set with SetExitFunc.
+func (g *Group) RunningTasks() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.running) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(g.running))
+	for _, idx := range slices.Sorted(maps.Keys(g.running)) {
+		names = append(names, g.running[idx])
+	}
+
+	return names
+}
+
+// Cancel cancels the group's Context with ErrCanceled as its cause, which
+// context.Cause reports, so that functions watching the Context and calls to
+// Go blocked on the group's limit unwind; the latter record the Context's
+// error. Cancel does not itself record an error for Wait to return, but the
+// group no longer counts as having completed cleanly: Succeeded reports false
+// and the OnError callbacks run with ErrCanceled. It has no effect once the
+// Context is canceled, nor for a Group without a Context.
+//
+// Once Wait has returned, Cancel only releases a Context kept by
+// DisableCancelOnWait, as Wait would have canceled it: the group's outcome
+// stays as it was, and the OnCancel and AfterCancel callbacks do not run.
+func (g *Group) Cancel() {
+	g.mu.Lock()
+	waited := g.waited && !g.compat
+	if !waited && g.cancel != nil && !g.canceled() {
+		g.cancelErr = ErrCanceled
+	}
+	g.mu.Unlock()
+
+	if waited {
+		g.release()
+		return
+	}
+
+	g.abort(ErrCanceled)
+}
+
+// Close shuts the group down, so that it can serve as an io.Closer: it cancels
+// the group as Cancel does, then waits for its functions and runs Finally as
+// Wait does, returning the error Wait returns.
+func (g *Group) Close() error {
+	g.Cancel()
+	return g.Wait()
+}
+
+// Pending returns the number of functions passed to Go that are waiting for a
+// slot under the group's limit, whether in a blocked call to Go or in the
+// buffer set by SetBuffer.
+func (g *Group) Pending() int {
+	return int(g.pending.Load())
+}
+
+// Deadline returns the deadline of the Context associated with the group, as
+// its Deadline method does, so that producers can check how much time remains
+// before submitting more work. For a Group not created with a Context, it
+// returns the zero time and false.
+func (g *Group) Deadline() (deadline time.Time, ok bool) {
+	if g.ctx == nil {
+		return time.Time{}, false
+	}
+
+	return g.ctx.Deadline()
+}
+
+// withCancel derives the group's Context from parent.
+func (g *Group) withCancel(parent context.Context) {
+	g.parent = parent
+	g.ctx, g.cancel = context.WithCancelCause(parent)
+}
+
+func (g *Group) context() context.Context {
+	if g.ctx == nil {
+		return context.Background()
+	}
+
+	return g.ctx
+}
+
+// panicNilFunc panics for a nil function passed to method, one of Go and its
+// variants, rather than letting a goroutine crash on calling it.
+func panicNilFunc(method string) {
+	panic(fmt.Errorf("errgroup: %s called with nil function", method))
+}
+
+// nextIndex returns the submission index of a new function, recording the
+// time of the first one for Elapsed.
+func (g *Group) nextIndex() int64 {
+	idx := g.submitted.Add(1) - 1
+	if idx == 0 {
+		now := g.clock().Now()
+		g.stats.begin.Store(&now)
+	}
+
+	return idx
+}
+
+// submit starts f as soon as the limit allows, or, if the group is at its limit
+// and its buffer has room, leaves f waiting in the buffer and returns. src is
+// the function the caller was given, which f may wrap, for FirstErrorStack to
+// name. If f is dropped without being called, skip, unless nil, is called
+// with the error recorded in its place; otherwise done, unless nil, is called
+// as for start.
+func (g *Group) submit(name string, src any, f func() error, skip, done func(err error)) {
+	if g.closed() {
+		skipped(skip, ErrGroupClosed)
+		return
+	}
+
+	idx := g.nextIndex()
+
+	if g.sem == nil || g.buffer == nil {
+		if err := g.acquire(); err != nil {
+			skipped(skip, err)
+			return
+		}
+		g.launch(idx, name, src, f, skip, done)
+		return
+	}
+
+	if g.sem.TryAcquire(1) {
+		g.launch(idx, name, src, f, skip, done)
+		return
+	}
+
+	g.buffer <- token{}
+	g.track()
+
+	ctx := g.context()
+	g.pending.Add(1)
+	go func() {
+		defer g.untrack()
+		defer func() { <-g.buffer }()
+
+		err := g.sem.Acquire(ctx, 1)
+		g.pending.Add(-1)
+		if err != nil {
+			g.record(err, nil)
+			skipped(skip, err)
+			return
+		}
+
+		g.launch(idx, name, src, f, skip, done)
+	}()
+}
+
+// skipped calls skip, unless nil, for a function dropped with err recorded in
+// its place.
+func skipped(skip func(err error), err error) {
+	if skip != nil {
+		skip(err)
+	}
+}
+
+// launch starts f, which holds a slot under the group's limit, once the rate
+// set by SetRate allows. Waiting for the rate only after the slot, a function
+// skipped while waiting for a slot does not use up the rate. If the group's
+// Context is done first, throttle records its error, the slot is given back,
+// and skip is called as for submit.
+func (g *Group) launch(idx int64, name string, src any, f func() error, skip, done func(err error)) {
+	if err := g.throttle(); err != nil {
+		if g.sem != nil {
+			g.sem.Release(1)
+		}
+		skipped(skip, err)
+		return
+	}
+
+	g.start(idx, name, src, f, done)
+}
+
+// closed reports whether Wait has returned, recording ErrGroupClosed if so.
+func (g *Group) closed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.waited || g.compat {
+		return false
+	}
+
+	g.appendErr(ErrGroupClosed)
+
+	return true
+}
+
+// acquire takes a slot under the group's limit, blocking until one is free or
+// the group's Context is done. In the latter case it records the Context's
+// error and returns it.
+func (g *Group) acquire() error {
+	if g.sem == nil {
+		return nil
+	}
+
+	if g.sem.TryAcquire(1) {
+		return nil
+	}
+
+	g.pending.Add(1)
+	defer g.pending.Add(-1)
+
+	ctx := g.context()
+	if g.compat {
+		// x/sync/errgroup blocks on the limit regardless of the Context.
+		ctx = context.Background()
+	}
+
+	if err := g.sem.Acquire(ctx, 1); err != nil {
+		g.record(err, nil)
+		return err
+	}
+
+	return nil
+}
+
+// start calls f in a new goroutine. idx is its position in submission order,
+// and src is as for submit. done, unless nil, is called with the error the
+// group records for f, as replaced by a panic handler or middleware, or nil.
+func (g *Group) start(idx int64, name string, src any, f func() error, done func(err error)) {
+	g.startLifetime()
+
+	g.track()
+	g.active.Add(1)
+
+	go func() {
+		defer g.finish()
+
+		err := g.run(idx, name, f)
+		if done != nil {
+			done(err)
+		}
+
+		if err != nil {
+			g.recordAt(idx, err, src)
+		} else if g.firstSuccess {
+			g.succeed()
+		}
+	}()
+}
+
+// run calls f between the task hooks and returns its error, labeled with name
+// if there is one.
+func (g *Group) run(idx int64, name string, f func() error) error {
+	g.startOnce.Do(func() {
+		g.mu.Lock()
+		onStart := g.onStart
+		g.mu.Unlock()
+
+		for _, fn := range onStart {
+			fn()
+		}
+	})
+
+	g.mu.Lock()
+	onStart, onFinish := g.onTaskStart, g.onTaskFinish
+	middleware := g.middleware
+	if name != "" {
+		if g.running == nil {
+			g.running = make(map[int64]string)
+		}
+		g.running[idx] = name
+	}
+	g.mu.Unlock()
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		f = middleware[i](f)
+	}
+
+	for _, fn := range onStart {
+		fn()
+	}
+
+	begin := g.clock().Now()
+	g.stats.taskStarted(begin)
+
+	err := g.call(f)
+	if name != "" {
+		g.mu.Lock()
+		delete(g.running, idx)
+		g.mu.Unlock()
+	}
+	if err != nil && name != "" {
+		err = fmt.Errorf("task %q: %w", name, err)
+	}
+
+	end := g.clock().Now()
+	dur := end.Sub(begin)
+	g.stats.taskFinished(idx, name, err, end, dur)
+
+	for _, fn := range onFinish {
+		fn(err, dur)
+	}
+
+	return err
+}
+
+// record records err, returned by the function src if src is not nil, and
+// cancels the group if it is the first error. For the first error returned by
+// a function, it also captures the stack of the calling goroutine.
+func (g *Group) record(err error, src any) {
+	g.mu.Lock()
+	g.appendErr(err)
+	g.mu.Unlock()
+
+	if g.propagate && g.up != nil {
+		g.up.record(err, nil)
+	}
+
+	first := false
+	g.errOnce.Do(func() {
+		var stack []byte
+		if src != nil {
+			stack = captureStack(src, err)
+		}
+
+		g.mu.Lock()
+		g.err = err
+		g.errStack = stack
+		g.closeErrChan()
+		g.mu.Unlock()
+
+		first = true
+	})
+
+	// Cancel outside of errOnce, since OnCancel callbacks may call back into
+	// the group and record another error.
+	if first && !g.keepRunning {
+		g.abort(err)
+	}
+}
+
+// appendErr adds err to the recorded errors, or counts it as dropped if
+// SetMaxErrors' bound is reached. g.mu must be held.
+func (g *Group) appendErr(err error) {
+	g.lastErr = err
+	if g.maxErrors > 0 && len(g.errs) >= g.maxErrors {
+		g.dropped++
+		return
+	}
+
+	g.errs = append(g.errs, err)
+	g.errsLate = append(g.errsLate, g.canceled())
+}
+
+// recordAt records err, returned by src, the idx-th function submitted,
+// keeping track of the earliest submitted function to fail.
+func (g *Group) recordAt(idx int64, err error, src any) {
+	g.mu.Lock()
+	if g.lowestErr == nil || idx < g.lowestIdx {
+		g.lowestErr, g.lowestIdx = err, idx
+	}
+	g.mu.Unlock()
+
+	g.record(err, src)
+}
+
+// succeed records that a function passed to Go returned nil and cancels the
+// group, for a Group created with WithFirstSuccess.
+func (g *Group) succeed() {
+	g.mu.Lock()
+	g.succeeded = true
+	g.mu.Unlock()
+
+	g.abort(errSucceeded)
+}
+
+// abort cancels the group's Context because of cause and, the first time it
+// is called, runs the OnCancel callbacks. It does nothing for a Group without
+// a Context.
+func (g *Group) abort(cause error) {
+	if g.cancel == nil {
+		return
+	}
+
+	g.setReason(cause)
+	g.cancel(cause)
+
+	if !g.aborted.CompareAndSwap(false, true) {
+		return
+	}
+
+	g.mu.Lock()
+	onCancel := g.onCancel
+	g.mu.Unlock()
+
+	for _, fn := range onCancel {
+		fn(cause)
+	}
+}
+
+func (g *Group) finish() {
+	g.active.Add(-1)
+
+	if g.sem != nil {
+		g.sem.Release(1)
+	}
+
+	g.untrack()
+}
+
+func (g *Group) runFinally() {
+	g.finallyOnce.Do(func() {
+		g.mu.Lock()
+		var finally []func() error
+		if len(g.finally)+len(g.onSuccess)+len(g.onError) > 0 {
+			finally = g.outcomeCallbacks()
+		}
+		g.mu.Unlock()
+
+		if len(finally) == 0 {
+			return
+		}
+
+		var err error
+		if g.finallyLimit > 0 {
+			err = g.callFinallyTimeout(finally, g.finallyLimit)
+		} else {
+			var errs []error
+			g.callFinally(finally, &errs)
+			err = errors.Join(errs...)
+		}
+
+		g.mu.Lock()
+		g.finallyErr = err
+		g.mu.Unlock()
+	})
+}
+
+// outcomeCallbacks returns the Finally callbacks preceded, in the order they
+// are called, by the OnSuccess or OnError callbacks matching the group's
+// outcome so far. g.mu must be held.
+func (g *Group) outcomeCallbacks() []func() error {
+	cause := g.outcome()
+	finally := append([]func() error(nil), g.finally...)
+	if cause == nil {
+		for i := len(g.onSuccess) - 1; i >= 0; i-- {
+			finally = append(finally, g.onSuccess[i])
+		}
+		return finally
+	}
+
+	for i := len(g.onError) - 1; i >= 0; i-- {
+		fn := g.onError[i]
+		finally = append(finally, func() error { return fn(cause) })
+	}
+
+	return finally
+}
+
+// outcome returns the error that ended the group, as passed to the OnError
+// callbacks, or nil if it has completed cleanly so far. Once Wait has returned,
+// it is fixed. g.mu must be held.
+func (g *Group) outcome() error {
+	if g.waited {
+		return g.cause
+	}
+
+	// As in result, a function that succeeded in a Group created with
+	// WithFirstSuccess outweighs those that failed, and the parent's
+	// cancellation.
+	cause := g.err
+	if g.firstSuccess && g.succeeded {
+		cause = nil
+	}
+	for _, err := range []error{g.sigErr, g.lifetimeErr, g.cancelErr} {
+		if cause == nil {
+			cause = err
+		}
+	}
+	if cause == nil && !g.succeeded && g.parent != nil {
+		cause = g.parent.Err()
+	}
+
+	return cause
+}
+
+// callFinallyTimeout calls the callbacks in a new goroutine and returns their
+// combined error, or an error matching ErrFinallyTimeout if they do not return
+// within d.
+func (g *Group) callFinallyTimeout(finally []func() error, d time.Duration) error {
+	if len(finally) == 0 {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		var errs []error
+		g.callFinally(finally, &errs)
+		done <- errors.Join(errs...)
+	}()
+
+	t := g.clock().NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-t.C():
+		return fmt.Errorf("%w after %v", ErrFinallyTimeout, d)
+	}
+}
+
+// callFinally calls the last callback in finally and then, deferred, the rest,
+// so that a panicking callback does not prevent earlier ones from running.
+// Panics are recovered like those of functions passed to Go.
+func (g *Group) callFinally(finally []func() error, errs *[]error) {
+	if len(finally) == 0 {
+		return
+	}
+
+	defer g.callFinally(finally[:len(finally)-1], errs)
+
+	if err := g.call(finally[len(finally)-1]); err != nil {
+		*errs = append(*errs, err)
+	}
+}
+
+// failed reports whether a function passed to Go has returned an error.
+func (g *Group) failed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.err != nil
+}
+
+// joinDropped joins to err, if it is not nil, an ErrErrorsDropped error telling
+// how many errors were dropped, if any. g.mu must be held.
+func (g *Group) joinDropped(err error) error {
+	if err == nil || g.dropped == 0 {
+		return err
+	}
+
+	return errors.Join(err, fmt.Errorf("%w: %d more after the first %d", ErrErrorsDropped, g.dropped, g.maxErrors))
+}
+
+// filterErrors returns the recorded errors that match none of the ignored
+// ones. g.mu must be held.
+func (g *Group) filterErrors() []error {
+	if len(g.ignored) == 0 {
+		return g.errs
+	}
+
+	var errs []error
+	for _, err := range g.errs {
+		if !g.isIgnored(err) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func (g *Group) isIgnored(err error) bool {
+	for _, target := range g.ignored {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// result returns the error to be returned by Wait.
+func (g *Group) result() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var err error
+	switch {
+	case g.firstSuccess:
+		if !g.succeeded {
+			err = errors.Join(g.errs...)
+			err = g.joinDropped(err)
+		}
+	case g.strategy == FirstError:
+		err = g.err
+	case g.strategy == FirstSubmittedError:
+		err = g.lowestErr
+		if err == nil {
+			err = g.err
+		}
+	case g.strategy == LastError:
+		err = g.lastErr
+	case g.strategy == AllErrors:
+		err = errors.Join(g.filterErrors()...)
+		err = g.joinDropped(err)
+	}
+
+	if g.sigErr != nil {
+		if err == nil {
+			err = g.sigErr
+		} else {
+			err = errors.Join(err, g.sigErr)
+		}
+	}
+
+	if g.lifetimeErr != nil {
+		if err == nil {
+			err = g.lifetimeErr
+		} else {
+			err = errors.Join(err, g.lifetimeErr)
+		}
+	}
+
+	if err == nil && !g.succeeded && g.parent != nil && !g.compat {
+		err = g.parent.Err()
+	}
+
+	if g.finallyErr != nil {
+		if err == nil {
+			err = g.finallyErr
+		} else {
+			err = errors.Join(err, g.finallyErr)
+		}
+	}
+
+	return err
 }
@@ -8,9 +8,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/rdeusser/errgroup"
 )
@@ -174,3 +181,2486 @@ func TestWithContext(t *testing.T) {
 		}
 	}
 }
+
+func TestWithContextNoFinally(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, _ := errgroup.WithContext(context.Background())
+	g.Go(func() error { return errDoom })
+	g.Go(func() error { return nil })
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+}
+
+func TestGoLimit(t *testing.T) {
+	const limit = 10
+
+	g := new(errgroup.Group)
+	g.SetLimit(limit)
+
+	var active int32
+	for i := 0; i <= 1<<10; i++ {
+		g.Go(func() error {
+			n := atomic.AddInt32(&active, 1)
+			if n > limit {
+				return fmt.Errorf("saw %d active goroutines; want ≤ %d", n, limit)
+			}
+			time.Sleep(1 * time.Microsecond) // Give other goroutines a chance to increment active.
+			atomic.AddInt32(&active, -1)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetLimitWhileActive(t *testing.T) {
+	g := new(errgroup.Group)
+	g.SetLimit(1)
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release
+		return nil
+	})
+
+	defer func() {
+		close(release)
+		g.Wait()
+	}()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("SetLimit with active goroutines did not panic")
+		}
+	}()
+
+	g.SetLimit(2)
+}
+
+func TestTryGo(t *testing.T) {
+	g := new(errgroup.Group)
+	n := 42
+	g.SetLimit(42)
+	ch := make(chan struct{})
+	fn := func() error {
+		ch <- struct{}{}
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		if !g.TryGo(fn) {
+			t.Fatalf("TryGo should succeed but got fail at %d-th call.", i)
+		}
+	}
+	if g.TryGo(fn) {
+		t.Fatalf("TryGo is expected to fail but succeeded.")
+	}
+	go func() {
+		for i := 0; i < n; i++ {
+			<-ch
+		}
+	}()
+	g.Wait()
+	g.Reset()
+
+	if !g.TryGo(fn) {
+		t.Fatalf("TryGo should succeed but got fail after all goroutines.")
+	}
+	go func() { <-ch }()
+	g.Wait()
+	g.Reset()
+
+	// Switch limit.
+	g.SetLimit(1)
+	if !g.TryGo(fn) {
+		t.Fatalf("TryGo should succeed but got failed.")
+	}
+	if g.TryGo(fn) {
+		t.Fatalf("TryGo should fail but succeeded.")
+	}
+	go func() { <-ch }()
+	g.Wait()
+	g.Reset()
+
+	// Block all calls.
+	g.SetLimit(0)
+	for i := 0; i < 1<<10; i++ {
+		if g.TryGo(fn) {
+			t.Fatalf("TryGo should fail but succeeded.")
+		}
+	}
+	g.Wait()
+}
+
+func TestTryGoError(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetLimit(1)
+
+	if !g.TryGo(func() error { return errDoom }) {
+		t.Fatalf("TryGo should succeed but got fail.")
+	}
+
+	<-ctx.Done()
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+}
+
+func TestWithAllErrors(t *testing.T) {
+	errs := []error{
+		errors.New("errgroup_test: 1"),
+		errors.New("errgroup_test: 2"),
+		errors.New("errgroup_test: 3"),
+	}
+
+	g, ctx := errgroup.WithAllErrors(context.Background())
+	for _, err := range errs {
+		g.Go(func() error { return err })
+	}
+
+	err := g.Wait()
+	for _, want := range errs {
+		if !errors.Is(err, want) {
+			t.Errorf("g.Wait() = %v; want it to contain %v", err, want)
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Errorf("ctx.Done() was not closed")
+	}
+}
+
+func TestWithAllErrorsNoErrors(t *testing.T) {
+	g, _ := errgroup.WithAllErrors(context.Background())
+	g.Go(func() error { return nil })
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() = %v; want nil", err)
+	}
+}
+
+func TestWithContextFirstErrorOnly(t *testing.T) {
+	errs := []error{
+		errors.New("errgroup_test: 1"),
+		errors.New("errgroup_test: 2"),
+		errors.New("errgroup_test: 3"),
+	}
+
+	g, _ := errgroup.WithContext(context.Background())
+	for _, err := range errs {
+		g.Go(func() error { return err })
+	}
+
+	err := g.Wait()
+
+	matched := 0
+	for _, e := range errs {
+		if err == e {
+			matched++
+		}
+	}
+	if matched != 1 {
+		t.Errorf("g.Wait() = %v; want exactly one of %v", err, errs)
+	}
+}
+
+func TestGoCtx(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, ctx := errgroup.WithContext(context.Background())
+
+	var taskCtx context.Context
+	g.GoCtx(func(ctx context.Context) error {
+		taskCtx = ctx
+		return errDoom
+	})
+	g.GoCtx(func(ctx context.Context) error {
+		<-ctx.Done() // Blocks Wait unless the first error cancels ctx.
+		return nil
+	})
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+
+	if taskCtx != ctx {
+		t.Errorf("GoCtx passed %v; want the group Context %v", taskCtx, ctx)
+	}
+
+	select {
+	case <-taskCtx.Done():
+	default:
+		t.Errorf("ctx.Done() was not closed after the first error")
+	}
+}
+
+func TestGoCtxZeroGroup(t *testing.T) {
+	g := new(errgroup.Group)
+
+	var taskCtx context.Context
+	g.GoCtx(func(ctx context.Context) error {
+		taskCtx = ctx
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() = %v; want nil", err)
+	}
+
+	if taskCtx != context.Background() {
+		t.Errorf("GoCtx passed %v; want context.Background()", taskCtx)
+	}
+}
+
+func TestFinally(t *testing.T) {
+	errs := []error{
+		errors.New("errgroup_test: 1"),
+		errors.New("errgroup_test: 2"),
+		errors.New("errgroup_test: 3"),
+	}
+
+	g := new(errgroup.Group)
+
+	var order []int
+	for i, err := range errs {
+		i, err := i, err
+		g.Finally(func() error {
+			order = append(order, i)
+			return err
+		})
+	}
+	g.Finally(nil)
+
+	g.Go(func() error { return nil })
+
+	err := g.Wait()
+	for _, want := range errs {
+		if !errors.Is(err, want) {
+			t.Errorf("g.Wait() = %v; want it to contain %v", err, want)
+		}
+	}
+
+	if want := []int{2, 1, 0}; fmt.Sprint(order) != fmt.Sprint(want) {
+		t.Errorf("Finally callbacks ran in order %v; want %v", order, want)
+	}
+}
+
+func TestFinallyCtx(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	for _, want := range []error{nil, errDoom} {
+		g, _ := errgroup.WithContext(context.Background())
+
+		var cause error
+		g.FinallyCtx(func(ctx context.Context) error {
+			cause = context.Cause(ctx)
+			return nil
+		})
+		g.FinallyCtx(nil)
+
+		g.Go(func() error { return want })
+		g.Wait()
+
+		if cause != want {
+			t.Errorf("context.Cause(ctx) in FinallyCtx = %v; want %v", cause, want)
+		}
+	}
+}
+
+func TestFinallyErrorChain(t *testing.T) {
+	errTask := errors.New("errgroup_test: task")
+	errFinally := errors.New("errgroup_test: finally")
+
+	g, _ := errgroup.WithContext(context.Background())
+	g.Finally(func() error { return errFinally })
+	g.Go(func() error { return errTask })
+
+	err := g.Wait()
+	for _, want := range []error{errTask, errFinally} {
+		if !errors.Is(err, want) {
+			t.Errorf("g.Wait() = %v; want it to match %v", err, want)
+		}
+	}
+}
+
+func TestActive(t *testing.T) {
+	const n = 3
+
+	g := new(errgroup.Group)
+	if got := g.Active(); got != 0 {
+		t.Errorf("g.Active() = %d before Go; want 0", got)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	for i := 0; i < n; i++ {
+		g.Go(func() error {
+			started <- struct{}{}
+			<-release
+			return nil
+		})
+	}
+	for i := 0; i < n; i++ {
+		<-started
+	}
+
+	if got := g.Active(); got != n {
+		t.Errorf("g.Active() = %d with blocked tasks; want %d", got, n)
+	}
+
+	close(release)
+	g.Wait()
+
+	if got := g.Active(); got != 0 {
+		t.Errorf("g.Active() = %d after Wait; want 0", got)
+	}
+}
+
+// chanSemaphore is a Semaphore of fixed capacity built on a buffered channel.
+type chanSemaphore chan struct{}
+
+func (s chanSemaphore) Acquire(ctx context.Context, n int64) error {
+	for i := int64(0); i < n; i++ {
+		select {
+		case s <- struct{}{}:
+		case <-ctx.Done():
+			s.Release(i)
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s chanSemaphore) TryAcquire(n int64) bool {
+	for i := int64(0); i < n; i++ {
+		select {
+		case s <- struct{}{}:
+		default:
+			s.Release(i)
+			return false
+		}
+	}
+	return true
+}
+
+func (s chanSemaphore) Release(n int64) {
+	for i := int64(0); i < n; i++ {
+		<-s
+	}
+}
+
+func TestSetSemaphore(t *testing.T) {
+	const limit, n = 2, 20
+
+	sem := make(chanSemaphore, limit)
+	g1 := new(errgroup.Group)
+	g1.SetSemaphore(sem)
+	g2, _ := errgroup.New(context.Background(), errgroup.SharedLimit(sem))
+
+	var active, maxActive atomic.Int32
+	task := func() error {
+		n := active.Add(1)
+		for {
+			max := maxActive.Load()
+			if n <= max || maxActive.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		active.Add(-1)
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for _, g := range []*errgroup.Group{g1, g2} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				g.Go(task)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := g1.Wait(); err != nil {
+		t.Errorf("g1.Wait() = %v; want nil", err)
+	}
+	if err := g2.Wait(); err != nil {
+		t.Errorf("g2.Wait() = %v; want nil", err)
+	}
+
+	if got := maxActive.Load(); got > limit {
+		t.Errorf("saw %d functions running at once across both groups; want at most %d", got, limit)
+	}
+	if len(sem) != 0 {
+		t.Errorf("%d units of the semaphore still held; want 0", len(sem))
+	}
+}
+
+func TestResizeSharedSemaphoreWhileActive(t *testing.T) {
+	sem := make(chanSemaphore, 2)
+	g := new(errgroup.Group)
+	g.SetSemaphore(sem)
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release
+		return nil
+	})
+
+	defer func() {
+		close(release)
+		g.Wait()
+		if len(sem) != 0 {
+			t.Errorf("%d units of the semaphore still held; want 0", len(sem))
+		}
+	}()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Resize of a shared semaphore with active goroutines did not panic")
+		}
+	}()
+
+	g.Resize(4)
+}
+
+func TestResize(t *testing.T) {
+	g := new(errgroup.Group)
+	g.SetLimit(2)
+
+	// waitActive waits for the number of active goroutines to settle at n.
+	waitActive := func(n int) {
+		t.Helper()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for g.Active() != n && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if got := g.Active(); got != n {
+			t.Fatalf("g.Active() = %d; want %d", got, n)
+		}
+	}
+
+	release := make(chan struct{})
+	submitted := make(chan struct{})
+	go func() {
+		defer close(submitted)
+		for i := 0; i < 10; i++ {
+			g.Go(func() error {
+				<-release
+				return nil
+			})
+		}
+	}()
+
+	waitActive(2)
+
+	g.Resize(5)
+	waitActive(5)
+
+	g.Resize(1)
+	for i := 0; i < 4; i++ {
+		release <- struct{}{}
+	}
+	waitActive(1)
+
+	g.Resize(-1)
+	close(release)
+	<-submitted
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() = %v; want nil", err)
+	}
+}
+
+func TestPending(t *testing.T) {
+	const limit, blocked = 2, 3
+
+	g := new(errgroup.Group)
+	g.SetLimit(limit)
+
+	release := make(chan struct{})
+	for i := 0; i < limit; i++ {
+		g.Go(func() error {
+			<-release
+			return nil
+		})
+	}
+
+	var submitted sync.WaitGroup
+	for i := 0; i < blocked; i++ {
+		submitted.Add(1)
+		go func() {
+			defer submitted.Done()
+			g.Go(func() error { return nil })
+		}()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for g.Pending() != blocked && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := g.Pending(); got != blocked {
+		t.Errorf("g.Pending() = %d with a saturated limit; want %d", got, blocked)
+	}
+
+	close(release)
+	submitted.Wait()
+	g.Wait()
+
+	if got := g.Pending(); got != 0 {
+		t.Errorf("g.Pending() = %d after Wait; want 0", got)
+	}
+}
+
+func TestWithLimit(t *testing.T) {
+	const limit = 2
+
+	errDoom := errors.New("group_test: doomed")
+
+	g, ctx := errgroup.WithLimit(context.Background(), limit)
+
+	var active int32
+	for i := 0; i < 100; i++ {
+		g.Go(func() error {
+			n := atomic.AddInt32(&active, 1)
+			defer atomic.AddInt32(&active, -1)
+			if n > limit {
+				return fmt.Errorf("saw %d active goroutines; want ≤ %d", n, limit)
+			}
+			time.Sleep(1 * time.Microsecond)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	g, ctx = errgroup.WithLimit(context.Background(), limit)
+	g.Go(func() error { return errDoom })
+	<-ctx.Done()
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+}
+
+func TestWithLimitUnlimited(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		g, _ := errgroup.WithLimit(context.Background(), n)
+
+		const tasks = 10
+		var wg sync.WaitGroup
+		wg.Add(tasks)
+		for i := 0; i < tasks; i++ {
+			g.Go(func() error {
+				wg.Done()
+				wg.Wait() // Blocks unless every task runs at once.
+				return nil
+			})
+		}
+		g.Wait()
+	}
+}
+
+func TestWithContextParentCanceled(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	cases := []struct {
+		taskErr error
+		want    error
+	}{
+		{taskErr: nil, want: context.DeadlineExceeded},
+		{taskErr: errDoom, want: errDoom},
+	}
+
+	for _, tc := range cases {
+		parent, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+
+		g, ctx := errgroup.WithContext(parent)
+		taskErr := tc.taskErr
+		g.Go(func() error {
+			<-ctx.Done()
+			return taskErr
+		})
+
+		if err := g.Wait(); err != tc.want {
+			t.Errorf("g.Wait() = %v; want %v", err, tc.want)
+		}
+
+		cancel()
+	}
+}
+
+func TestGoNamed(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g := new(errgroup.Group)
+	g.GoNamed("fetch", func() error { return nil })
+	g.GoNamed("upload", func() error { return errDoom })
+
+	err := g.Wait()
+	if !errors.Is(err, errDoom) {
+		t.Errorf("g.Wait() = %v; want it to match %v", err, errDoom)
+	}
+
+	if err == nil || !strings.Contains(err.Error(), `task "upload"`) {
+		t.Errorf("g.Wait() = %v; want it to name the upload task", err)
+	}
+}
+
+func TestGoNilFunc(t *testing.T) {
+	cases := []struct {
+		method string
+		call   func(g *errgroup.Group)
+	}{
+		{"Go", func(g *errgroup.Group) { g.Go(nil) }},
+		{"GoNamed", func(g *errgroup.Group) { g.GoNamed("fetch", nil) }},
+		{"GoCtx", func(g *errgroup.Group) { g.GoCtx(nil) }},
+		{"TryGo", func(g *errgroup.Group) { g.TryGo(nil) }},
+		{"GoRetry", func(g *errgroup.Group) { g.GoRetry(3, 0, nil) }},
+		{"GoEach", func(g *errgroup.Group) { errgroup.GoEach[int](g, []int{1}, nil) }},
+		{"GoWithResult", func(g *errgroup.Group) { errgroup.GoWithResult[int](g, nil) }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.method, func(t *testing.T) {
+			g := new(errgroup.Group)
+
+			func() {
+				defer func() {
+					want := "errgroup: " + tc.method + " called with nil function"
+					if r := recover(); fmt.Sprint(r) != want {
+						t.Errorf("recovered %v; want %q", r, want)
+					}
+				}()
+				tc.call(g)
+			}()
+
+			if n := g.Active(); n != 0 {
+				t.Errorf("g.Active() = %d; want 0", n)
+			}
+
+			done := make(chan error, 1)
+			go func() { done <- g.Wait() }()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("g.Wait() = %v; want nil", err)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("g.Wait() blocked; the WaitGroup was left incremented")
+			}
+		})
+	}
+}
+
+func TestRunningTasks(t *testing.T) {
+	g := new(errgroup.Group)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	g.GoNamed("fetch", func() error { return nil })
+	g.GoNamed("upload", func() error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	g.Go(func() error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+
+	<-started
+	<-started
+	for g.Active() > 2 {
+		runtime.Gosched()
+	}
+
+	if got, want := g.RunningTasks(), []string{"upload"}; !slices.Equal(got, want) {
+		t.Errorf("g.RunningTasks() = %q; want %q", got, want)
+	}
+
+	close(release)
+	g.Wait()
+
+	if got := g.RunningTasks(); got != nil {
+		t.Errorf("g.RunningTasks() after Wait = %q; want nil", got)
+	}
+}
+
+func TestOnCancel(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	cases := []struct {
+		errs []error
+		want error
+	}{
+		{errs: []error{nil, nil}, want: nil},
+		{errs: []error{errDoom, errDoom, nil}, want: errDoom},
+	}
+
+	for _, tc := range cases {
+		g, _ := errgroup.WithContext(context.Background())
+
+		var calls int32
+		var cause error
+		g.OnCancel(func(err error) {
+			atomic.AddInt32(&calls, 1)
+			cause = err
+			g.Active() // Must not deadlock.
+			g.Go(func() error { return errDoom })
+		})
+
+		for _, err := range tc.errs {
+			g.Go(func() error { return err })
+		}
+		g.Wait()
+
+		want := int32(0)
+		if tc.want != nil {
+			want = 1
+		}
+		if calls != want {
+			t.Errorf("OnCancel callback ran %d times for errs %v; want %d", calls, tc.errs, want)
+		}
+		if cause != tc.want {
+			t.Errorf("OnCancel cause = %v for errs %v; want %v", cause, tc.errs, tc.want)
+		}
+	}
+}
+
+func TestOnCancelReentrant(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	cases := []struct {
+		name  string
+		setup func(g *errgroup.Group)
+		call  func(g *errgroup.Group)
+	}{
+		{
+			// The failing function still holds the only slot, so Go records
+			// the canceled Context's error.
+			name:  "Limit",
+			setup: func(g *errgroup.Group) { g.SetLimit(1) },
+			call:  func(g *errgroup.Group) { g.Go(func() error { return nil }) },
+		},
+		{
+			name:  "Capacity",
+			setup: func(g *errgroup.Group) { g.SetCapacity(1) },
+			call:  func(g *errgroup.Group) { g.GoWeighted(5, func() error { return nil }) },
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, _ := errgroup.WithContext(context.Background())
+			tc.setup(g)
+			g.OnCancel(func(error) { tc.call(g) })
+
+			g.Go(func() error { return errDoom })
+
+			done := make(chan error, 1)
+			go func() { done <- g.Wait() }()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, errDoom) {
+					t.Errorf("g.Wait() = %v; want it to match %v", err, errDoom)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("g.Wait() deadlocked on an OnCancel callback calling into the group")
+			}
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, _ := errgroup.WithContext(context.Background())
+	g.Go(func() error { return errDoom })
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+
+	g.Reset()
+
+	g.GoCtx(func(ctx context.Context) error { return ctx.Err() })
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() after Reset = %v; want nil", err)
+	}
+}
+
+func TestResetWhileActive(t *testing.T) {
+	g := new(errgroup.Group)
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release
+		return nil
+	})
+
+	defer func() {
+		close(release)
+		g.Wait()
+	}()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Reset with active goroutines did not panic")
+		}
+	}()
+
+	g.Reset()
+}
+
+func TestResetWhilePending(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(g *errgroup.Group, release chan struct{})
+	}{
+		{
+			name: "GoAfter",
+			setup: func(g *errgroup.Group, release chan struct{}) {
+				g.GoAfter(release, func() error { return nil })
+			},
+		},
+		{
+			name: "Buffer",
+			setup: func(g *errgroup.Group, release chan struct{}) {
+				g.SetLimit(1)
+				g.SetBuffer(1)
+				g.Go(func() error {
+					<-release
+					return nil
+				})
+				for g.Active() == 0 {
+					runtime.Gosched()
+				}
+				g.Go(func() error { return nil })
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := new(errgroup.Group)
+			release := make(chan struct{})
+			tc.setup(g, release)
+
+			func() {
+				defer func() {
+					if r := recover(); r == nil {
+						t.Errorf("Reset with a function waiting to start did not panic")
+					}
+				}()
+				g.Reset()
+			}()
+
+			close(release)
+			g.Wait()
+		})
+	}
+}
+
+func TestDone(t *testing.T) {
+	g := new(errgroup.Group)
+
+	finally := false
+	g.Finally(func() error {
+		finally = true
+		return nil
+	})
+
+	release := make(chan struct{})
+	g.Go(func() error { return nil })
+	g.Go(func() error {
+		<-release
+		return nil
+	})
+
+	done := g.Done()
+	if g.Done() != done {
+		t.Errorf("g.Done() returned different channels")
+	}
+
+	go g.Wait()
+
+	select {
+	case <-done:
+		t.Fatalf("g.Done() was closed before the last task returned")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("g.Done() was not closed after the last task returned")
+	}
+
+	if !finally {
+		t.Errorf("g.Done() was closed before Finally ran")
+	}
+}
+
+func TestGoTimeout(t *testing.T) {
+	g := new(errgroup.Group)
+
+	g.GoTimeout(time.Hour, func(ctx context.Context) error { return ctx.Err() })
+	g.GoTimeout(10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if err := g.Wait(); err != context.DeadlineExceeded {
+		t.Errorf("g.Wait() = %v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestGoTimeoutFakeClock(t *testing.T) {
+	clock := errgroup.NewFakeClock()
+	g := new(errgroup.Group)
+	errgroup.SetClock(g, clock)
+
+	started := make(chan struct{})
+	g.GoTimeout(time.Minute, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	<-started
+	clock.Advance(time.Minute)
+
+	if err := g.Wait(); err != context.DeadlineExceeded {
+		t.Errorf("g.Wait() = %v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestOnLateError(t *testing.T) {
+	errLate := errors.New("group_test: late")
+
+	g := new(errgroup.Group)
+
+	late := make(chan error, 2)
+	g.OnLateError(func(err error) { late <- err })
+
+	release := make(chan struct{})
+	g.Go(func() error { return nil })
+	g.Go(func() error {
+		<-release
+		return errLate
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := g.WaitContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("g.WaitContext() = %v; want %v", err, context.DeadlineExceeded)
+	}
+
+	select {
+	case err := <-late:
+		t.Fatalf("late-error callback fired with %v before the task returned", err)
+	default:
+	}
+
+	close(release)
+
+	select {
+	case err := <-late:
+		if err != errLate {
+			t.Errorf("late-error callback got %v; want %v", err, errLate)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("late-error callback did not fire")
+	}
+
+	select {
+	case err := <-late:
+		t.Errorf("late-error callback fired again with %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestDoneBeforeGo(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g := new(errgroup.Group)
+	done := g.Done()
+
+	select {
+	case <-done:
+		t.Fatalf("g.Done() was closed before Wait was called")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	var ran atomic.Bool
+	g.Go(func() error {
+		ran.Store(true)
+		return errDoom
+	})
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+	if !ran.Load() {
+		t.Errorf("function passed to Go after Done did not run")
+	}
+
+	select {
+	case <-done:
+	default:
+		t.Errorf("g.Done() was not closed after Wait returned")
+	}
+}
+
+func TestWaitContext(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g := new(errgroup.Group)
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release
+		return errDoom
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := g.WaitContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("g.WaitContext() = %v; want %v", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+
+	if err := g.WaitContext(context.Background()); err != errDoom {
+		t.Errorf("g.WaitContext() = %v; want %v", err, errDoom)
+	}
+}
+
+func TestWaitContextEarlyReturn(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g := new(errgroup.Group)
+
+	var finally atomic.Bool
+	g.Finally(func() error {
+		finally.Store(true)
+		return nil
+	})
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := g.WaitContext(ctx); err != context.Canceled {
+		t.Errorf("g.WaitContext() = %v; want %v", err, context.Canceled)
+	}
+
+	close(release)
+	for g.Active() != 0 {
+		runtime.Gosched()
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if finally.Load() {
+		t.Errorf("Finally ran without a call to Wait")
+	}
+
+	g.Go(func() error { return errDoom })
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v, from a function passed to Go after WaitContext", err, errDoom)
+	}
+	if !finally.Load() {
+		t.Errorf("Finally did not run in Wait")
+	}
+}
+
+func TestWaitTwice(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, _ := errgroup.WithContext(context.Background())
+
+	calls := 0
+	g.Finally(func() error {
+		calls++
+		return nil
+	})
+
+	g.Go(func() error { return errDoom })
+
+	for i := 0; i < 2; i++ {
+		if err := g.Wait(); err != errDoom {
+			t.Errorf("g.Wait() #%d = %v; want %v", i+1, err, errDoom)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Finally callback ran %d times; want 1", calls)
+	}
+}
+
+func TestWaitTwiceParentCanceled(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	g, _ := errgroup.WithContext(parent)
+	g.Go(func() error { return nil })
+
+	if err := g.Wait(); err != nil {
+		t.Fatalf("g.Wait() = %v; want nil", err)
+	}
+
+	cancel()
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() after the parent was canceled = %v; want nil", err)
+	}
+	if !g.Succeeded() {
+		t.Errorf("g.Succeeded() after the parent was canceled = false; want true")
+	}
+	if g.Go(func() error { return nil }); !g.Succeeded() {
+		t.Errorf("g.Succeeded() after a call to Go after Wait = false; want true")
+	}
+}
+
+func TestSetErrorStrategy(t *testing.T) {
+	errs := []error{
+		errors.New("errgroup_test: 1"),
+		errors.New("errgroup_test: 2"),
+		errors.New("errgroup_test: 3"),
+	}
+
+	cases := []struct {
+		strategy errgroup.ErrorStrategy
+		want     []error
+	}{
+		{strategy: errgroup.FirstError, want: errs[:1]},
+		{strategy: errgroup.LastError, want: errs[2:]},
+		{strategy: errgroup.AllErrors, want: errs},
+		{strategy: errgroup.FirstSubmittedError, want: errs[:1]},
+	}
+
+	for _, tc := range cases {
+		g := new(errgroup.Group)
+		g.SetErrorStrategy(tc.strategy)
+
+		// Return the errors one at a time so that their order is known.
+		for i, err := range errs {
+			g.Go(func() error { return err })
+			waitErrors(g, i+1)
+		}
+
+		err := g.Wait()
+		for _, e := range errs {
+			if got, want := errors.Is(err, e), containsError(tc.want, e); got != want {
+				t.Errorf("strategy %d: errors.Is(%v, %v) = %t; want %t", tc.strategy, err, e, got, want)
+			}
+		}
+	}
+}
+
+func TestFirstSubmittedError(t *testing.T) {
+	const n = 10
+
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = fmt.Errorf("errgroup_test: %d", i)
+	}
+
+	for run := 0; run < 100; run++ {
+		g, _ := errgroup.New(context.Background(), errgroup.Strategy(errgroup.FirstSubmittedError))
+
+		ready := make(chan struct{})
+		for i, err := range errs {
+			i, err := i, err
+			g.Go(func() error {
+				<-ready
+				if i == 0 {
+					// Give the others a head start.
+					time.Sleep(time.Millisecond)
+				}
+				return err
+			})
+		}
+		close(ready)
+
+		if err := g.Wait(); err != errs[0] {
+			t.Fatalf("run %d: g.Wait() = %v; want %v", run, err, errs[0])
+		}
+	}
+}
+
+// waitErrors waits until g has recorded n errors.
+func waitErrors(g *errgroup.Group, n int) {
+	for len(g.Errors()) < n {
+		runtime.Gosched()
+	}
+}
+
+func containsError(errs []error, target error) bool {
+	for _, err := range errs {
+		if err == target {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSetGoMiddleware(t *testing.T) {
+	errTask := errors.New("errgroup_test: task")
+
+	var calls atomic.Int32
+	var mu sync.Mutex
+	var order []string
+
+	g := new(errgroup.Group)
+	g.SetLimit(1) // keep each task's middleware calls together in order
+	g.SetGoMiddleware(func(next func() error) func() error {
+		return func() error {
+			calls.Add(1)
+			mu.Lock()
+			order = append(order, "outer")
+			mu.Unlock()
+
+			return next()
+		}
+	})
+	g.SetGoMiddleware(func(next func() error) func() error {
+		return func() error {
+			mu.Lock()
+			order = append(order, "inner")
+			mu.Unlock()
+
+			if err := next(); err != nil {
+				return fmt.Errorf("wrapped: %w", err)
+			}
+			return nil
+		}
+	})
+	g.SetGoMiddleware(nil)
+
+	const n = 3
+	for i := 0; i < n; i++ {
+		g.Go(func() error { return nil })
+	}
+	g.Go(func() error { return errTask })
+
+	err := g.Wait()
+	if !errors.Is(err, errTask) || !strings.HasPrefix(err.Error(), "wrapped: ") {
+		t.Errorf("g.Wait() = %v; want %v wrapped by the middleware", err, errTask)
+	}
+
+	if got := calls.Load(); got != n+1 {
+		t.Errorf("middleware ran %d times; want %d", got, n+1)
+	}
+
+	for i := 0; i < len(order); i += 2 {
+		if order[i] != "outer" || order[i+1] != "inner" {
+			t.Fatalf("middleware order = %v; want outer before inner for each task", order)
+		}
+	}
+}
+
+func TestOnStart(t *testing.T) {
+	g := new(errgroup.Group)
+
+	var calls atomic.Int32
+	initialized := make(chan struct{})
+	g.OnStart(func() {
+		calls.Add(1)
+		close(initialized)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				g.Go(func() error {
+					select {
+					case <-initialized:
+						return nil
+					default:
+						return errors.New("group_test: task ran before OnStart")
+					}
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() = %v; want nil", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("OnStart callback ran %d times; want 1", n)
+	}
+
+	g = new(errgroup.Group)
+	g.OnStart(func() { t.Errorf("OnStart callback ran for an empty group") })
+	g.Wait()
+}
+
+func TestTaskHooks(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g := new(errgroup.Group)
+	g.RecoverPanics(true)
+
+	var started int32
+	g.OnTaskStart(func() { atomic.AddInt32(&started, 1) })
+
+	var mu sync.Mutex
+	var finished []error
+	g.OnTaskFinish(func(err error, dur time.Duration) {
+		if dur <= 0 {
+			t.Errorf("OnTaskFinish duration = %v; want > 0", dur)
+		}
+
+		mu.Lock()
+		finished = append(finished, err)
+		mu.Unlock()
+	})
+
+	g.Go(func() error {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	g.Go(func() error { return errDoom })
+	g.Go(func() error { panic("errgroup_test: panic") })
+	g.Wait()
+
+	if started != 3 {
+		t.Errorf("OnTaskStart ran %d times; want 3", started)
+	}
+
+	if len(finished) != 3 {
+		t.Fatalf("OnTaskFinish ran %d times; want 3", len(finished))
+	}
+
+	var nils, dooms, panics int
+	for _, err := range finished {
+		var pe *errgroup.PanicError
+		switch {
+		case err == nil:
+			nils++
+		case err == errDoom:
+			dooms++
+		case errors.As(err, &pe):
+			panics++
+		}
+	}
+	if nils != 1 || dooms != 1 || panics != 1 {
+		t.Errorf("OnTaskFinish errors = %v; want one each of nil, %v, and a *PanicError", finished, errDoom)
+	}
+}
+
+func TestDisableCancelOnError(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, ctx := errgroup.WithContext(context.Background())
+	g.DisableCancelOnError()
+
+	failed := make(chan struct{})
+	g.Go(func() error {
+		defer close(failed)
+		return errDoom
+	})
+
+	var completed int32
+	for i := 0; i < 2; i++ {
+		g.Go(func() error {
+			<-failed
+			time.Sleep(10 * time.Millisecond)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			atomic.AddInt32(&completed, 1)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+	if completed != 2 {
+		t.Errorf("%d tasks ran to completion; want 2", completed)
+	}
+}
+
+func TestWithContextCause(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, ctx := errgroup.WithContext(context.Background())
+	g.Go(func() error { return errDoom })
+	g.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Wait()
+
+	if cause := context.Cause(ctx); cause != errDoom {
+		t.Errorf("context.Cause(ctx) = %v; want %v", cause, errDoom)
+	}
+
+	g, ctx = errgroup.WithContext(context.Background())
+	g.Go(func() error { return nil })
+	g.Wait()
+
+	if cause := context.Cause(ctx); cause != context.Canceled {
+		t.Errorf("context.Cause(ctx) after a clean Wait = %v; want %v", cause, context.Canceled)
+	}
+}
+
+func TestRecordedErrors(t *testing.T) {
+	errFirst := errors.New("errgroup_test: first")
+	errLater := errors.New("errgroup_test: later")
+
+	g, ctx := errgroup.WithAllErrors(context.Background())
+
+	proceed := make(chan struct{})
+	g.Go(func() error {
+		<-ctx.Done()
+		<-proceed
+		return errLater
+	})
+	g.Go(func() error { return errFirst })
+
+	waitErrors(g, 1)
+	close(proceed)
+	g.Wait()
+
+	got := g.RecordedErrors()
+	want := []errgroup.RecordedError{
+		{Err: errFirst, AfterCancel: false},
+		{Err: errLater, AfterCancel: true},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("g.RecordedErrors() = %v; want %v", got, want)
+	}
+
+	g, _ = errgroup.New(context.Background(), errgroup.Strategy(errgroup.AllErrors), errgroup.NoCancelOnError())
+	g.Go(func() error { return errFirst })
+	waitErrors(g, 1)
+	g.Go(func() error { return errLater })
+	g.Wait()
+
+	for _, rec := range g.RecordedErrors() {
+		if rec.AfterCancel {
+			t.Errorf("%v recorded after cancellation in a group that does not cancel on error", rec.Err)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	errs := []error{
+		errors.New("errgroup_test: 1"),
+		errors.New("errgroup_test: 2"),
+		errors.New("errgroup_test: 3"),
+	}
+
+	g := new(errgroup.Group)
+	if got := g.Errors(); len(got) != 0 {
+		t.Errorf("g.Errors() = %v before Go; want none", got)
+	}
+
+	for i, err := range errs {
+		g.Go(func() error { return err })
+		g.Go(func() error { return nil })
+		waitErrors(g, i+1)
+	}
+	g.Wait()
+
+	got := g.Errors()
+	if fmt.Sprint(got) != fmt.Sprint(errs) {
+		t.Fatalf("g.Errors() = %v; want %v", got, errs)
+	}
+
+	got[0] = nil
+	if again := g.Errors(); again[0] != errs[0] {
+		t.Errorf("modifying the result of g.Errors() changed it to %v", again)
+	}
+}
+
+func TestWithFirstSuccess(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, ctx := errgroup.WithFirstSuccess(context.Background())
+
+	g.Go(func() error { return errDoom })
+	g.Go(func() error {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+
+	var canceled int32
+	for i := 0; i < 2; i++ {
+		g.Go(func() error {
+			select {
+			case <-ctx.Done():
+				atomic.AddInt32(&canceled, 1)
+				return ctx.Err()
+			case <-time.After(time.Second):
+				return nil
+			}
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() = %v; want nil", err)
+	}
+	if canceled != 2 {
+		t.Errorf("%d slower tasks were canceled; want 2", canceled)
+	}
+}
+
+func TestWithFirstSuccessAllFail(t *testing.T) {
+	errs := []error{
+		errors.New("errgroup_test: 1"),
+		errors.New("errgroup_test: 2"),
+		errors.New("errgroup_test: 3"),
+	}
+
+	g, _ := errgroup.WithFirstSuccess(context.Background())
+	for _, err := range errs {
+		g.Go(func() error { return err })
+	}
+
+	err := g.Wait()
+	for _, want := range errs {
+		if !errors.Is(err, want) {
+			t.Errorf("g.Wait() = %v; want it to contain %v", err, want)
+		}
+	}
+}
+
+func TestWithFirstSuccessOutcome(t *testing.T) {
+	g, _ := errgroup.WithFirstSuccess(context.Background())
+
+	committed := false
+	g.OnSuccess(func() error {
+		committed = true
+		return nil
+	})
+	var onErr error
+	g.OnError(func(err error) error {
+		onErr = err
+		return nil
+	})
+	var finallyCause error
+	g.FinallyCtx(func(ctx context.Context) error {
+		finallyCause = context.Cause(ctx)
+		return nil
+	})
+
+	failed := make(chan struct{})
+	g.Go(func() error {
+		defer close(failed)
+		return errors.New("errgroup_test: mirror down")
+	})
+	g.Go(func() error {
+		<-failed
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		t.Fatalf("g.Wait() = %v; want nil", err)
+	}
+	if !committed {
+		t.Errorf("OnSuccess callback did not run")
+	}
+	if onErr != nil {
+		t.Errorf("OnError callback ran with %v; want it not to run", onErr)
+	}
+	if finallyCause != nil {
+		t.Errorf("FinallyCtx cause = %v; want nil", finallyCause)
+	}
+	if !g.Succeeded() {
+		t.Errorf("g.Succeeded() = false; want true")
+	}
+}
+
+func TestEnableCancel(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	for _, enable := range []bool{false, true} {
+		g := new(errgroup.Group)
+		if enable {
+			g.EnableCancel()
+		}
+
+		var cause error
+		g.OnCancel(func(err error) { cause = err })
+
+		failed := make(chan struct{})
+		g.Go(func() error {
+			defer close(failed)
+			return errDoom
+		})
+
+		canceled := false
+		g.GoCtx(func(ctx context.Context) error {
+			<-failed
+			select {
+			case <-ctx.Done():
+				canceled = true
+			case <-time.After(100 * time.Millisecond):
+			}
+			return nil
+		})
+
+		if err := g.Wait(); err != errDoom {
+			t.Errorf("EnableCancel %t: g.Wait() = %v; want %v", enable, err, errDoom)
+		}
+		if canceled != enable {
+			t.Errorf("EnableCancel %t: task observed cancellation = %t", enable, canceled)
+		}
+		if (cause == errDoom) != enable {
+			t.Errorf("EnableCancel %t: OnCancel cause = %v", enable, cause)
+		}
+	}
+}
+
+func TestSetBuffer(t *testing.T) {
+	const buffer = 2
+
+	g := new(errgroup.Group)
+	g.SetLimit(1)
+	g.SetBuffer(buffer)
+
+	release := make(chan struct{})
+	var ran int32
+	task := func() error {
+		<-release
+		atomic.AddInt32(&ran, 1)
+		return nil
+	}
+
+	submitted := make(chan struct{})
+	go func() {
+		for i := 0; i < 1+buffer; i++ {
+			g.Go(task)
+		}
+		close(submitted)
+	}()
+
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		t.Fatalf("Go blocked before the buffer was full")
+	}
+
+	blocked := make(chan struct{})
+	go func() {
+		g.Go(task)
+		close(blocked)
+	}()
+
+	select {
+	case <-blocked:
+		t.Fatalf("Go did not block with a full buffer")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	<-blocked
+	g.Wait()
+
+	if ran != 2+buffer {
+		t.Errorf("%d tasks ran; want %d", ran, 2+buffer)
+	}
+}
+
+func TestSetBufferCanceled(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, _ := errgroup.WithContext(context.Background())
+	g.SetLimit(1)
+	g.SetBuffer(1)
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release
+		return errDoom
+	})
+
+	ran := false
+	g.Go(func() error {
+		ran = true
+		return nil
+	})
+
+	close(release)
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+	if ran {
+		t.Errorf("buffered task ran after the group was canceled")
+	}
+	if errs := g.Errors(); len(errs) != 2 || errs[1] != context.Canceled {
+		t.Errorf("g.Errors() = %v; want [%v %v]", errs, errDoom, context.Canceled)
+	}
+}
+
+func TestGoLimitCanceled(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	g, _ := errgroup.WithContext(parent)
+	g.SetLimit(1)
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release
+		return nil
+	})
+
+	ran := false
+	returned := make(chan struct{})
+	go func() {
+		g.Go(func() error {
+			ran = true
+			return nil
+		})
+		close(returned)
+	}()
+
+	cancel()
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatalf("Go blocked on a full limit after the group was canceled")
+	}
+
+	close(release)
+
+	if err := g.Wait(); err != context.Canceled {
+		t.Errorf("g.Wait() = %v; want %v", err, context.Canceled)
+	}
+	if ran {
+		t.Errorf("blocked task ran after the group was canceled")
+	}
+}
+
+func TestSetFinallyOrder(t *testing.T) {
+	cases := []struct {
+		order        errgroup.FinallyOrder
+		wantCanceled bool
+	}{
+		{order: errgroup.FinallyBeforeCancel, wantCanceled: false},
+		{order: errgroup.FinallyAfterCancel, wantCanceled: true},
+	}
+
+	for _, tc := range cases {
+		g, ctx := errgroup.New(context.Background(), errgroup.FinallyOrdering(tc.order))
+
+		watched := make(chan struct{})
+		go func() {
+			<-ctx.Done()
+			close(watched)
+		}()
+
+		var canceled bool
+		g.Finally(func() error {
+			select {
+			case <-watched:
+				canceled = true
+			case <-time.After(10 * time.Millisecond):
+			}
+			return nil
+		})
+
+		g.Go(func() error { return nil })
+		g.Wait()
+
+		if canceled != tc.wantCanceled {
+			t.Errorf("order %d: watcher saw cancellation during Finally = %t; want %t", tc.order, canceled, tc.wantCanceled)
+		}
+	}
+}
+
+func TestOnSuccessOnError(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	for _, fail := range []bool{false, true} {
+		g := new(errgroup.Group)
+
+		var calls []string
+		g.Finally(func() error {
+			calls = append(calls, "finally")
+			return nil
+		})
+		g.OnSuccess(func() error {
+			calls = append(calls, "success")
+			return nil
+		})
+		g.OnError(func(err error) error {
+			if err != errDoom {
+				t.Errorf("OnError callback got %v; want %v", err, errDoom)
+			}
+			calls = append(calls, "error")
+			return nil
+		})
+
+		g.Go(func() error {
+			if fail {
+				return errDoom
+			}
+			return nil
+		})
+		g.Wait()
+
+		want := []string{"success", "finally"}
+		if fail {
+			want = []string{"error", "finally"}
+		}
+		if fmt.Sprint(calls) != fmt.Sprint(want) {
+			t.Errorf("fail=%t: callbacks ran as %v; want %v", fail, calls, want)
+		}
+	}
+}
+
+func TestOnSuccessError(t *testing.T) {
+	errCommit := errors.New("group_test: commit failed")
+
+	g := new(errgroup.Group)
+	g.OnSuccess(func() error { return errCommit })
+	g.Go(func() error { return nil })
+
+	if err := g.Wait(); !errors.Is(err, errCommit) {
+		t.Errorf("g.Wait() = %v; want %v", err, errCommit)
+	}
+}
+
+func TestSetFinallyTimeout(t *testing.T) {
+	g := new(errgroup.Group)
+	g.SetFinallyTimeout(10 * time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	g.Finally(func() error {
+		<-release
+		return nil
+	})
+	g.Go(func() error { return nil })
+
+	returned := make(chan error, 1)
+	go func() { returned <- g.Wait() }()
+
+	select {
+	case err := <-returned:
+		if !errors.Is(err, errgroup.ErrFinallyTimeout) {
+			t.Errorf("g.Wait() = %v; want it to match ErrFinallyTimeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait blocked on a Finally callback past its timeout")
+	}
+}
+
+func TestSetFinallyTimeoutInTime(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g := new(errgroup.Group)
+	g.SetFinallyTimeout(5 * time.Second)
+	g.Finally(func() error { return errDoom })
+	g.Go(func() error { return nil })
+
+	if err := g.Wait(); !errors.Is(err, errDoom) {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+}
+
+func TestErrChan(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g := new(errgroup.Group)
+	errc := g.ErrChan()
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release // A slow straggler.
+		return nil
+	})
+	g.Go(func() error { return errDoom })
+
+	select {
+	case err := <-errc:
+		if err != errDoom {
+			t.Errorf("<-g.ErrChan() = %v; want %v", err, errDoom)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("g.ErrChan() did not receive the error while a task was running")
+	}
+
+	if _, ok := <-errc; ok {
+		t.Errorf("g.ErrChan() was not closed after the error")
+	}
+
+	close(release)
+	g.Wait()
+
+	if late := g.ErrChan(); late != errc {
+		t.Errorf("g.ErrChan() returned different channels")
+	}
+}
+
+func TestErrChanClean(t *testing.T) {
+	g := new(errgroup.Group)
+	g.Go(func() error { return nil })
+	g.Wait()
+
+	select {
+	case err, ok := <-g.ErrChan():
+		if ok {
+			t.Errorf("<-g.ErrChan() = %v; want it closed", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("g.ErrChan() was not closed after a clean Wait")
+	}
+}
+
+func TestSubGroup(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	// A failure in a propagating subgroup cancels the parent.
+	g, ctx := errgroup.WithContext(context.Background())
+	g.Go(func() error {
+		sub, _ := g.SubGroup(errgroup.PropagateErrors())
+		sub.Go(func() error { return errDoom })
+		return sub.Wait()
+	})
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+
+	// A failure in the parent cancels the subgroup.
+	g, _ = errgroup.WithContext(context.Background())
+	sub, subCtx := g.SubGroup()
+	sub.Go(func() error {
+		<-subCtx.Done()
+		return subCtx.Err()
+	})
+	g.Go(func() error { return errDoom })
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+	if err := sub.Wait(); err != context.Canceled {
+		t.Errorf("sub.Wait() = %v; want %v", err, context.Canceled)
+	}
+
+	// A failure in a non-propagating subgroup leaves the parent alone.
+	g, ctx = errgroup.WithContext(context.Background())
+	sub, _ = g.SubGroup()
+	sub.Go(func() error { return errDoom })
+	sub.Wait()
+	if err := ctx.Err(); err != nil {
+		t.Errorf("parent ctx.Err() = %v; want nil", err)
+	}
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() = %v; want nil", err)
+	}
+}
+
+func TestSetMaxErrors(t *testing.T) {
+	const max, n = 3, 10
+
+	g := new(errgroup.Group)
+	g.SetErrorStrategy(errgroup.AllErrors)
+	g.DisableCancelOnError()
+	g.SetMaxErrors(max)
+
+	for i := 0; i < n; i++ {
+		g.Go(func() error { return fmt.Errorf("group_test: error %d", i) })
+	}
+
+	err := g.Wait()
+	if !errors.Is(err, errgroup.ErrErrorsDropped) {
+		t.Errorf("g.Wait() = %v; want it to match ErrErrorsDropped", err)
+	}
+	if want := fmt.Sprintf("%d more after the first %d", n-max, max); !strings.Contains(err.Error(), want) {
+		t.Errorf("g.Wait() = %v; want it to contain %q", err, want)
+	}
+	if got := len(g.Errors()); got != max {
+		t.Errorf("len(g.Errors()) = %d; want %d", got, max)
+	}
+	if got := g.DroppedErrors(); got != n-max {
+		t.Errorf("g.DroppedErrors() = %d; want %d", got, n-max)
+	}
+
+	g.Reset()
+	g.Go(func() error { return errors.New("group_test: only") })
+	if err := g.Wait(); errors.Is(err, errgroup.ErrErrorsDropped) {
+		t.Errorf("g.Wait() after Reset = %v; want it not to match ErrErrorsDropped", err)
+	}
+	if got := g.DroppedErrors(); got != 0 {
+		t.Errorf("g.DroppedErrors() after Reset = %d; want 0", got)
+	}
+}
+
+func TestSetIgnoredErrors(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, ctx := errgroup.New(context.Background(),
+		errgroup.Strategy(errgroup.AllErrors),
+		errgroup.IgnoredErrors(context.Canceled),
+	)
+
+	failed := make(chan struct{})
+	g.Go(func() error {
+		defer close(failed)
+		return errDoom
+	})
+	for i := 0; i < 3; i++ {
+		g.Go(func() error {
+			<-failed
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}
+
+	err := g.Wait()
+	if !errors.Is(err, errDoom) {
+		t.Errorf("g.Wait() = %v; want it to contain %v", err, errDoom)
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Errorf("g.Wait() = %v; want it not to contain %v", err, context.Canceled)
+	}
+	if n := len(g.Errors()); n != 4 {
+		t.Errorf("len(g.Errors()) = %d; want 4", n)
+	}
+}
+
+func TestGoCancelable(t *testing.T) {
+	g := new(errgroup.Group)
+
+	cancelOne := g.GoCancelable(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	others := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		g.GoCancelable(func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				others <- ctx.Err()
+			case <-time.After(20 * time.Millisecond):
+				others <- nil
+			}
+			return nil
+		})
+	}
+
+	cancelOne()
+	cancelOne()
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() = %v; want nil", err)
+	}
+	cancelOne()
+
+	for i := 0; i < 2; i++ {
+		if err := <-others; err != nil {
+			t.Errorf("another task observed cancellation: %v", err)
+		}
+	}
+}
+
+func TestGoAfterWait(t *testing.T) {
+	g := new(errgroup.Group)
+	g.Go(func() error { return nil })
+	if err := g.Wait(); err != nil {
+		t.Fatalf("g.Wait() = %v; want nil", err)
+	}
+
+	ran := false
+	g.Go(func() error {
+		ran = true
+		return errors.New("errgroup_test: unobservable")
+	})
+	if g.TryGo(func() error { return nil }) {
+		t.Errorf("g.TryGo() after Wait = true; want false")
+	}
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() = %v; want nil", err)
+	}
+	if ran {
+		t.Errorf("function passed to Go after Wait was called")
+	}
+
+	got := g.Errors()
+	if len(got) != 2 || got[0] != errgroup.ErrGroupClosed || got[1] != errgroup.ErrGroupClosed {
+		t.Errorf("g.Errors() = %v; want [%v %v]", got, errgroup.ErrGroupClosed, errgroup.ErrGroupClosed)
+	}
+
+	g.Reset()
+	g.Go(func() error {
+		ran = true
+		return nil
+	})
+	g.Wait()
+	if !ran {
+		t.Errorf("function passed to Go after Reset was not called")
+	}
+}
+
+func TestWaitAll(t *testing.T) {
+	errs := []error{
+		errors.New("errgroup_test: 1"),
+		errors.New("errgroup_test: 2"),
+		errors.New("errgroup_test: 3"),
+	}
+
+	g, _ := errgroup.New(context.Background(), errgroup.NoCancelOnError())
+	for i, err := range errs {
+		g.Go(func() error { return err })
+		waitErrors(g, i+1)
+	}
+
+	first, all := g.WaitAll()
+	if first != errs[0] {
+		t.Errorf("first = %v; want %v", first, errs[0])
+	}
+	for _, err := range errs {
+		if !errors.Is(all, err) {
+			t.Errorf("errors.Is(all, %v) = false; want true", err)
+		}
+	}
+
+	g = new(errgroup.Group)
+	g.Go(func() error { return nil })
+	if first, all := g.WaitAll(); first != nil || all != nil {
+		t.Errorf("g.WaitAll() = %v, %v; want nil, nil", first, all)
+	}
+}
+
+type taskIDKey struct{}
+
+func TestGoWithValues(t *testing.T) {
+	g, ctx := errgroup.WithContext(context.Background())
+
+	const n = 10
+	seen := make([]any, n)
+	for i := 0; i < n; i++ {
+		g.GoWithValues(map[any]any{taskIDKey{}: i}, func(ctx context.Context) error {
+			seen[i] = ctx.Value(taskIDKey{})
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("g.Wait() = %v; want nil", err)
+	}
+
+	for i, v := range seen {
+		if v != i {
+			t.Errorf("task %d saw task ID %v; want %d", i, v, i)
+		}
+	}
+	if v := ctx.Value(taskIDKey{}); v != nil {
+		t.Errorf("group Context carries task ID %v; want none", v)
+	}
+
+	errDoom := errors.New("group_test: doomed")
+	g, _ = errgroup.WithContext(context.Background())
+	g.GoWithValues(map[any]any{taskIDKey{}: 0}, func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	g.Go(func() error { return errDoom })
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+}
+
+func TestSucceeded(t *testing.T) {
+	g := new(errgroup.Group)
+	g.Go(func() error { return nil })
+	if g.Succeeded() {
+		t.Errorf("g.Succeeded() before Wait = true; want false")
+	}
+	g.Wait()
+	if !g.Succeeded() {
+		t.Errorf("g.Succeeded() after a clean Wait = false; want true")
+	}
+
+	g = new(errgroup.Group)
+	g.Go(func() error { return errors.New("group_test: doomed") })
+	g.Wait()
+	if g.Succeeded() {
+		t.Errorf("g.Succeeded() after a task error = true; want false")
+	}
+
+	g = new(errgroup.Group)
+	g.Finally(func() error { return errors.New("group_test: doomed") })
+	g.Wait()
+	if g.Succeeded() {
+		t.Errorf("g.Succeeded() after a Finally error = true; want false")
+	}
+
+	parent, cancel := context.WithCancel(context.Background())
+	cancel()
+	g, _ = errgroup.WithContext(parent)
+	g.Wait()
+	if g.Succeeded() {
+		t.Errorf("g.Succeeded() after the parent was canceled = true; want false")
+	}
+}
+
+func TestGoAfter(t *testing.T) {
+	g := new(errgroup.Group)
+
+	ready := make(chan struct{})
+	var ran atomic.Bool
+	g.GoAfter(ready, func() error {
+		ran.Store(true)
+		return nil
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	if ran.Load() {
+		t.Fatalf("function ran before ready fired")
+	}
+
+	close(ready)
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() = %v; want nil", err)
+	}
+	if !ran.Load() {
+		t.Errorf("function did not run after ready fired")
+	}
+}
+
+func TestGoAfterCanceled(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, _ := errgroup.WithContext(context.Background())
+
+	ready := make(chan struct{})
+	defer close(ready)
+
+	ran := false
+	g.GoAfter(ready, func() error {
+		ran = true
+		return nil
+	})
+	g.Go(func() error { return errDoom })
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+	if ran {
+		t.Errorf("function ran although the group was canceled before ready fired")
+	}
+}
+
+func TestWaitUnused(t *testing.T) {
+	groups := make([]errgroup.Group, 101)
+
+	var i int
+	var err error
+	allocs := testing.AllocsPerRun(100, func() {
+		err = groups[i].Wait()
+		i++
+	})
+
+	if err != nil {
+		t.Errorf("g.Wait() on an unused Group = %v; want nil", err)
+	}
+	if allocs != 0 {
+		t.Errorf("g.Wait() on an unused Group allocated %v times; want 0", allocs)
+	}
+}
+
+func BenchmarkWaitUnused(b *testing.B) {
+	groups := make([]errgroup.Group, b.N)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range groups {
+		groups[i].Wait()
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	var zero errgroup.Group
+	if d, ok := zero.Deadline(); !d.IsZero() || ok {
+		t.Errorf("zero Group Deadline() = %v, %t; want zero time, false", d, ok)
+	}
+
+	want := time.Now().Add(time.Hour)
+	parent, cancel := context.WithDeadline(context.Background(), want)
+	defer cancel()
+
+	g, _ := errgroup.WithContext(parent)
+	if d, ok := g.Deadline(); !d.Equal(want) || !ok {
+		t.Errorf("g.Deadline() = %v, %t; want %v, true", d, ok, want)
+	}
+
+	g, _ = errgroup.WithContext(context.Background())
+	if d, ok := g.Deadline(); !d.IsZero() || ok {
+		t.Errorf("g.Deadline() without a deadline = %v, %t; want zero time, false", d, ok)
+	}
+}
+
+func TestClose(t *testing.T) {
+	errShutdown := errors.New("group_test: shut down")
+
+	g, _ := errgroup.WithContext(context.Background())
+
+	finally := false
+	g.Finally(func() error {
+		finally = true
+		return nil
+	})
+
+	g.GoCtx(func(ctx context.Context) error {
+		<-ctx.Done()
+		return errShutdown
+	})
+	g.GoCtx(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	var closer io.Closer = g
+	if err := closer.Close(); !errors.Is(err, errShutdown) {
+		t.Errorf("g.Close() = %v; want %v", err, errShutdown)
+	}
+	if !finally {
+		t.Errorf("Finally callback did not run")
+	}
+	if n := g.Active(); n != 0 {
+		t.Errorf("g.Active() = %d after Close; want 0", n)
+	}
+}
+
+func TestCloseOutcome(t *testing.T) {
+	g, _ := errgroup.WithContext(context.Background())
+
+	committed := false
+	g.OnSuccess(func() error {
+		committed = true
+		return nil
+	})
+	var onErr error
+	g.OnError(func(err error) error {
+		onErr = err
+		return nil
+	})
+	var finallyCause error
+	g.FinallyCtx(func(ctx context.Context) error {
+		finallyCause = context.Cause(ctx)
+		return nil
+	})
+
+	g.GoCtx(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	if err := g.Close(); err != nil {
+		t.Errorf("g.Close() = %v; want nil", err)
+	}
+	if committed {
+		t.Errorf("OnSuccess callback ran after Close")
+	}
+	if onErr != errgroup.ErrCanceled {
+		t.Errorf("OnError callback got %v; want %v", onErr, errgroup.ErrCanceled)
+	}
+	if finallyCause != errgroup.ErrCanceled {
+		t.Errorf("FinallyCtx cause = %v; want %v", finallyCause, errgroup.ErrCanceled)
+	}
+	if g.Succeeded() {
+		t.Errorf("g.Succeeded() after Close = true; want false")
+	}
+}
+
+func TestDisableCancelOnWait(t *testing.T) {
+	g, ctx := errgroup.New(context.Background(), errgroup.NoCancelOnWait())
+	g.Go(func() error { return nil })
+
+	if err := g.Wait(); err != nil {
+		t.Fatalf("g.Wait() = %v; want nil", err)
+	}
+	if err := ctx.Err(); err != nil {
+		t.Errorf("ctx.Err() after a clean Wait = %v; want nil", err)
+	}
+
+	g.Cancel()
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("ctx.Err() after Cancel = %v; want %v", err, context.Canceled)
+	}
+
+	errDoom := errors.New("group_test: doomed")
+	g, ctx = errgroup.New(context.Background(), errgroup.NoCancelOnWait(), errgroup.NoCancelOnError())
+	g.Go(func() error { return errDoom })
+	g.Wait()
+
+	if cause := context.Cause(ctx); cause != context.Canceled {
+		t.Errorf("context.Cause(ctx) after a failed Wait = %v; want %v", cause, context.Canceled)
+	}
+}
+
+func TestDisableCancelOnWaitRelease(t *testing.T) {
+	g, ctx := errgroup.New(context.Background(), errgroup.NoCancelOnWait())
+
+	onCancel := 0
+	g.OnCancel(func(error) { onCancel++ })
+	afterCancel := make(chan struct{}, 1)
+	g.AfterCancel(func() { afterCancel <- struct{}{} })
+
+	g.Go(func() error { return nil })
+	if err := g.Wait(); err != nil {
+		t.Fatalf("g.Wait() = %v; want nil", err)
+	}
+
+	g.Cancel()
+	<-ctx.Done()
+
+	if onCancel != 0 {
+		t.Errorf("OnCancel callback ran %d times after releasing the Context; want 0", onCancel)
+	}
+	select {
+	case <-afterCancel:
+		t.Errorf("AfterCancel callback ran after releasing the Context")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if got := g.CancelReason().Kind; got != errgroup.ReasonCompleted {
+		t.Errorf("g.CancelReason().Kind = %v; want %v", got, errgroup.ReasonCompleted)
+	}
+	if !g.Succeeded() {
+		t.Errorf("g.Succeeded() = false; want true")
+	}
+}
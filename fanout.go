@@ -0,0 +1,84 @@
+package errgroup
+
+import "iter"
+
+// GoEach calls f for each of items in a new goroutine of g, as g.Go does,
+// respecting the group's limit.
+func GoEach[T any](g *Group, items []T, f func(item T) error) {
+	if f == nil {
+		panicNilFunc("GoEach")
+	}
+
+	for _, item := range items {
+		g.goWrapped(f, func() error { return f(item) })
+	}
+}
+
+// GoSeq calls f for each value pulled from seq in a new goroutine of g, as g.Go
+// does, respecting the group's limit. It stops pulling values once the group's
+// Context is done, so seq is not advanced past the value being handled when
+// the group is canceled.
+func GoSeq[T any](g *Group, seq iter.Seq[T], f func(v T) error) {
+	if f == nil {
+		panicNilFunc("GoSeq")
+	}
+
+	done := g.context().Done()
+	for v := range seq {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		g.goWrapped(f, func() error { return f(v) })
+	}
+}
+
+// GoN calls f n times, each in a new goroutine as Go does, passing it the
+// indices 0 through n-1, respecting the group's limit.
+func (g *Group) GoN(n int, f func(i int) error) {
+	if f == nil {
+		panicNilFunc("GoN")
+	}
+
+	for i := 0; i < n; i++ {
+		g.goWrapped(f, func() error { return f(i) })
+	}
+}
+
+// Consume starts workers goroutines in g, as g.Go does, that receive items from
+// in and call f for each of them, until in is closed or the group's Context is
+// done. A workers value less than 1 starts a single goroutine. An error
+// returned by f stops the goroutine that received the item and is recorded as
+// for any function passed to Go, which cancels the others if the group cancels
+// on error. Items still in in once consumption stops are not received.
+func Consume[T any](g *Group, in <-chan T, workers int, f func(item T) error) {
+	if f == nil {
+		panicNilFunc("Consume")
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	done := g.context().Done()
+	for i := 0; i < workers; i++ {
+		g.goWrapped(f, func() error {
+			for {
+				select {
+				case item, ok := <-in:
+					if !ok {
+						return nil
+					}
+
+					if err := f(item); err != nil {
+						return err
+					}
+				case <-done:
+					return nil
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package errgroup_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestGoEach(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g := new(errgroup.Group)
+	g.SetLimit(2)
+
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	errgroup.GoEach(g, []int{1, 2, 3, 4, 5}, func(i int) error {
+		if i == 3 {
+			return errDoom
+		}
+
+		mu.Lock()
+		seen[i] = true
+		mu.Unlock()
+
+		return nil
+	})
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+
+	for _, i := range []int{1, 2, 4, 5} {
+		if !seen[i] {
+			t.Errorf("f was not called for %d", i)
+		}
+	}
+}
+
+func TestGoN(t *testing.T) {
+	const n = 10
+
+	g := new(errgroup.Group)
+
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	g.GoN(n, func(i int) error {
+		mu.Lock()
+		seen[i] = true
+		mu.Unlock()
+
+		if i == 3 {
+			return fmt.Errorf("worker %d failed", i)
+		}
+		return nil
+	})
+
+	err := g.Wait()
+	if err == nil || err.Error() != "worker 3 failed" {
+		t.Errorf("g.Wait() = %v; want worker 3 failed", err)
+	}
+
+	if len(seen) != n {
+		t.Errorf("%d workers ran; want %d", len(seen), n)
+	}
+}
+
+func TestConsume(t *testing.T) {
+	const n = 100
+
+	in := make(chan int)
+	go func() {
+		for i := 0; i < n; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+
+	g := new(errgroup.Group)
+
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	errgroup.Consume(g, in, 4, func(i int) error {
+		mu.Lock()
+		seen[i] = true
+		mu.Unlock()
+
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		t.Fatalf("g.Wait() = %v; want nil", err)
+	}
+
+	if len(seen) != n {
+		t.Errorf("consumed %d items; want %d", len(seen), n)
+	}
+}
+
+func TestConsumeError(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	in := make(chan int)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			select {
+			case in <- i:
+			case <-time.After(100 * time.Millisecond):
+				// Nothing is consuming anymore.
+				return
+			}
+		}
+	}()
+
+	g, _ := errgroup.WithContext(context.Background())
+	errgroup.Consume(g, in, 4, func(i int) error {
+		if i == 10 {
+			return errDoom
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+
+	<-stopped
+}
+
+func TestGoSeq(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	var pulled atomic.Int32
+	seq := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			pulled.Add(1)
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	g, _ := errgroup.WithContext(context.Background())
+	g.SetLimit(1)
+
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	errgroup.GoSeq(g, seq, func(i int) error {
+		mu.Lock()
+		seen[i] = true
+		mu.Unlock()
+
+		if i == 5 {
+			return errDoom
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+
+	for i := 0; i <= 5; i++ {
+		if !seen[i] {
+			t.Errorf("f was not called for %d", i)
+		}
+	}
+
+	// With a limit of 1, the value after the failing one is pulled while Go
+	// blocks on the limit, and may still win the freed slot as the group is
+	// canceled; the value after that is pulled but not handled.
+	if n := pulled.Load(); n > 8 {
+		t.Errorf("pulled %d values after cancellation at the 6th; want at most 8", n)
+	}
+}
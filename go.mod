@@ -1 +1,3 @@
-module github.com/rdeusser/errgroup
\ No newline at end of file
+module github.com/rdeusser/errgroup
+
+go 1.23
@@ -0,0 +1,30 @@
+package errgroup
+
+import (
+	"log"
+	"runtime"
+)
+
+// WarnUnwaited is a debugging aid that logs a warning, with the standard
+// logger, if g is garbage collected after functions were passed to Go but
+// without Wait ever having been called, which usually means that their errors
+// went unobserved. It adds a finalizer to g, so it is off by default and meant
+// for tests and development builds.
+//
+// g must have been allocated on its own, as by new or WithContext, rather than
+// embedded in another value. The warning is best effort: like any finalizer,
+// it may run late or not at all, for example if g is reachable from a
+// callback registered on it.
+func (g *Group) WarnUnwaited() {
+	runtime.SetFinalizer(g, (*Group).warnUnwaited)
+}
+
+func (g *Group) warnUnwaited() {
+	g.mu.Lock()
+	waited := g.waited
+	g.mu.Unlock()
+
+	if n := g.submitted.Load(); n > 0 && !waited {
+		log.Printf("errgroup: Group garbage collected after Go was called %d times without a call to Wait", n)
+	}
+}
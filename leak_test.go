@@ -0,0 +1,115 @@
+package errgroup_test
+
+import (
+	"bytes"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+// syncBuffer is a bytes.Buffer safe for the finalizer goroutine to write to.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// captureLog redirects the standard logger to a buffer for the rest of the
+// test.
+func captureLog(t *testing.T) *syncBuffer {
+	t.Helper()
+
+	buf := new(syncBuffer)
+	out, flags := log.Writer(), log.Flags()
+	log.SetOutput(buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetFlags(flags)
+	})
+
+	return buf
+}
+
+// collect runs the garbage collector, and so pending finalizers, until cond
+// holds or about a second has passed.
+func collect(cond func() bool) {
+	for i := 0; i < 100 && !cond(); i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// startGroup passes a function to Go on a new Group, waits for it to return,
+// and then calls Wait only if wait is true, dropping the Group either way.
+func startGroup(warn, wait bool) {
+	g := new(errgroup.Group)
+	if warn {
+		g.WarnUnwaited()
+	}
+
+	done := make(chan struct{})
+	g.Go(func() error {
+		close(done)
+		return nil
+	})
+	<-done
+
+	if wait {
+		g.Wait()
+	}
+}
+
+func TestWarnUnwaited(t *testing.T) {
+	buf := captureLog(t)
+
+	startGroup(true, false)
+	collect(func() bool { return buf.String() != "" })
+
+	const want = "errgroup: Group garbage collected after Go was called 1 times without a call to Wait\n"
+	if got := buf.String(); got != want {
+		t.Errorf("logged %q; want %q", got, want)
+	}
+}
+
+func TestWarnUnwaitedSilent(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		warn, wait bool
+	}{
+		{name: "Disabled", warn: false, wait: false},
+		{name: "Waited", warn: true, wait: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := captureLog(t)
+
+			startGroup(tc.warn, tc.wait)
+			for i := 0; i < 5; i++ {
+				runtime.GC()
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if got := buf.String(); strings.Contains(got, "errgroup:") {
+				t.Errorf("logged %q; want nothing", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package errgroup
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrMaxLifetimeExceeded is returned by Wait when the group was canceled
+// because its maximum lifetime elapsed.
+var ErrMaxLifetimeExceeded = errors.New("errgroup: maximum lifetime exceeded")
+
+// SetMaxLifetime bounds how long the group may run. Once d has elapsed since
+// the first function was passed to Go, the group is canceled with
+// ErrMaxLifetimeExceeded as the cause, and Wait returns that error joined with
+// any other. A zero or negative d, the default, means no bound.
+//
+// SetMaxLifetime must be called before the first function is passed to Go.
+func (g *Group) SetMaxLifetime(d time.Duration) {
+	g.maxLifetime = d
+}
+
+// startLifetime arms the maximum lifetime timer the first time it is called.
+func (g *Group) startLifetime() {
+	if g.maxLifetime <= 0 {
+		return
+	}
+
+	g.lifetimeOnce.Do(func() {
+		t := g.clock().AfterFunc(g.maxLifetime, g.expire)
+
+		g.mu.Lock()
+		g.lifetime = t
+		g.mu.Unlock()
+	})
+}
+
+// stopLifetime stops the maximum lifetime timer, if it was armed.
+func (g *Group) stopLifetime() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.lifetime != nil {
+		g.lifetime.Stop()
+	}
+}
+
+func (g *Group) expire() {
+	g.mu.Lock()
+	g.lifetimeErr = ErrMaxLifetimeExceeded
+	g.mu.Unlock()
+
+	g.abort(ErrMaxLifetimeExceeded)
+}
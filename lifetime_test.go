@@ -0,0 +1,76 @@
+package errgroup_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestSetMaxLifetime(t *testing.T) {
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetMaxLifetime(10 * time.Millisecond)
+
+	g.Go(func() error {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Second):
+			return errors.New("errgroup_test: not canceled")
+		}
+	})
+
+	if err := g.Wait(); !errors.Is(err, errgroup.ErrMaxLifetimeExceeded) {
+		t.Errorf("g.Wait() = %v; want it to match ErrMaxLifetimeExceeded", err)
+	}
+
+	if cause := context.Cause(ctx); cause != errgroup.ErrMaxLifetimeExceeded {
+		t.Errorf("context.Cause(ctx) = %v; want %v", cause, errgroup.ErrMaxLifetimeExceeded)
+	}
+}
+
+func TestSetMaxLifetimeFinishedEarly(t *testing.T) {
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetMaxLifetime(10 * time.Millisecond)
+
+	g.Go(func() error { return nil })
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() = %v; want nil", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if cause := context.Cause(ctx); cause != context.Canceled {
+		t.Errorf("context.Cause(ctx) = %v; want %v", cause, context.Canceled)
+	}
+}
+
+func TestSetMaxLifetimeFakeClock(t *testing.T) {
+	clock := errgroup.NewFakeClock()
+	g, ctx := errgroup.WithContext(context.Background())
+	errgroup.SetClock(g, clock)
+	g.SetMaxLifetime(time.Hour)
+
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	clock.Advance(time.Hour - time.Nanosecond)
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("ctx.Err() = %v before the lifetime elapsed; want nil", err)
+	}
+
+	clock.Advance(time.Nanosecond)
+
+	if err := g.Wait(); !errors.Is(err, errgroup.ErrMaxLifetimeExceeded) {
+		t.Errorf("g.Wait() = %v; want it to match ErrMaxLifetimeExceeded", err)
+	}
+
+	if cause := context.Cause(ctx); cause != errgroup.ErrMaxLifetimeExceeded {
+		t.Errorf("context.Cause(ctx) = %v; want %v", cause, errgroup.ErrMaxLifetimeExceeded)
+	}
+}
@@ -0,0 +1,172 @@
+package errgroup
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// An Option configures a Group created with New.
+type Option func(g *Group)
+
+// New returns a new Group and an associated Context derived from ctx, as
+// WithContext does, configured by opts. The options are applied in order
+// before the Group is returned, so they are in effect for the first function
+// passed to Go.
+func New(ctx context.Context, opts ...Option) (*Group, context.Context) {
+	g := new(Group)
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.withCancel(ctx)
+
+	return g, g.ctx
+}
+
+// Limit returns an Option that limits the number of active goroutines, as
+// SetLimit does.
+func Limit(n int) Option {
+	return func(g *Group) { g.SetLimit(n) }
+}
+
+// SharedLimit returns an Option that limits the number of active goroutines
+// with sem, which other groups may share, as SetSemaphore does.
+func SharedLimit(sem Semaphore) Option {
+	return func(g *Group) { g.SetSemaphore(sem) }
+}
+
+// Capacity returns an Option that sets the total weight of functions passed to
+// GoWeighted that may run at once, as SetCapacity does.
+func Capacity(total int64) Option {
+	return func(g *Group) { g.SetCapacity(total) }
+}
+
+// MemoryBudget returns an Option that sets the total estimated memory of
+// functions passed to GoSized that may run at once, as SetMemoryBudget does.
+func MemoryBudget(total int64) Option {
+	return func(g *Group) { g.SetMemoryBudget(total) }
+}
+
+// Rate returns an Option that limits how fast the group starts functions, as
+// SetRate does.
+func Rate(perSecond float64) Option {
+	return func(g *Group) { g.SetRate(perSecond) }
+}
+
+// Buffer returns an Option that buffers functions passed to Go while the group
+// is at its limit, as SetBuffer does.
+func Buffer(n int) Option {
+	return func(g *Group) { g.SetBuffer(n) }
+}
+
+// PanicRecovery returns an Option that recovers panics in functions passed to
+// Go, as RecoverPanics(true) does.
+func PanicRecovery() Option {
+	return func(g *Group) { g.RecoverPanics(true) }
+}
+
+// Strategy returns an Option that sets how Wait combines errors, as
+// SetErrorStrategy does.
+func Strategy(s ErrorStrategy) Option {
+	return func(g *Group) { g.SetErrorStrategy(s) }
+}
+
+// IgnoredErrors returns an Option that leaves errors matching targets out of
+// the AllErrors result, as SetIgnoredErrors does.
+func IgnoredErrors(targets ...error) Option {
+	return func(g *Group) { g.SetIgnoredErrors(targets...) }
+}
+
+// NoCancelOnError returns an Option that keeps errors from canceling the
+// group, as DisableCancelOnError does.
+func NoCancelOnError() Option {
+	return func(g *Group) { g.DisableCancelOnError() }
+}
+
+// NoCancelOnWait returns an Option that keeps Wait from canceling the group's
+// Context after a clean run, as DisableCancelOnWait does.
+func NoCancelOnWait() Option {
+	return func(g *Group) { g.DisableCancelOnWait() }
+}
+
+// FirstSuccess returns an Option that makes the group stop at the first
+// success rather than the first error, as described for WithFirstSuccess.
+func FirstSuccess() Option {
+	return func(g *Group) {
+		g.keepRunning = true
+		g.firstSuccess = true
+	}
+}
+
+// MaxLifetime returns an Option that bounds how long the group may run, as
+// SetMaxLifetime does.
+func MaxLifetime(d time.Duration) Option {
+	return func(g *Group) { g.SetMaxLifetime(d) }
+}
+
+// Signals returns an Option that configures the group with a signal handler
+// and a stop channel, as described for WithSignalHandler, catching sigs or, if
+// none are given, SIGINT and SIGTERM.
+func Signals(sigs ...os.Signal) Option {
+	return func(g *Group) {
+		if len(sigs) == 0 {
+			sigs = defaultSignals
+		}
+
+		g.stop = make(chan struct{})
+		g.catchSignals = true
+		g.signals = sigs
+	}
+}
+
+// ShutdownTimeout returns an Option that bounds how long the group waits after
+// a caught signal, as SetShutdownTimeout does.
+func ShutdownTimeout(d time.Duration) Option {
+	return func(g *Group) { g.SetShutdownTimeout(d) }
+}
+
+// NoExitOnError returns an Option that keeps the signal handler from exiting
+// the program when a function has already failed, as DisableExitOnError does.
+func NoExitOnError() Option {
+	return func(g *Group) { g.DisableExitOnError() }
+}
+
+// FinallyOrdering returns an Option that sets whether Finally callbacks run
+// before or after the group's Context is canceled, as SetFinallyOrder does.
+func FinallyOrdering(o FinallyOrder) Option {
+	return func(g *Group) { g.SetFinallyOrder(o) }
+}
+
+// PropagateErrors returns an Option that makes a group created with SubGroup
+// record its errors in the group it was created from as well.
+func PropagateErrors() Option {
+	return func(g *Group) { g.propagate = true }
+}
+
+// FinallyTimeout returns an Option that bounds how long Wait waits for the
+// Finally callbacks, as SetFinallyTimeout does.
+func FinallyTimeout(d time.Duration) Option {
+	return func(g *Group) { g.SetFinallyTimeout(d) }
+}
+
+// Middleware returns an Option that wraps every function passed to Go with mw,
+// in order, as SetGoMiddleware does.
+func Middleware(mw ...func(next func() error) func() error) Option {
+	return func(g *Group) {
+		for _, m := range mw {
+			g.SetGoMiddleware(m)
+		}
+	}
+}
+
+// MaxErrors returns an Option that bounds the number of errors the group
+// retains, as SetMaxErrors does.
+func MaxErrors(n int) Option {
+	return func(g *Group) { g.SetMaxErrors(n) }
+}
+
+// TaskReports returns an Option that has the group keep a report of each
+// function, as EnableTaskReports does.
+func TaskReports() Option {
+	return func(g *Group) { g.EnableTaskReports() }
+}
@@ -0,0 +1,75 @@
+package errgroup_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestNew(t *testing.T) {
+	const limit = 2
+
+	errDoom := errors.New("group_test: doomed")
+
+	g, ctx := errgroup.New(context.Background(),
+		errgroup.Limit(limit),
+		errgroup.PanicRecovery(),
+		errgroup.Strategy(errgroup.AllErrors),
+		errgroup.MaxLifetime(time.Hour),
+		errgroup.NoCancelOnError(),
+	)
+
+	var active int32
+	for i := 0; i < 20; i++ {
+		g.Go(func() error {
+			n := atomic.AddInt32(&active, 1)
+			defer atomic.AddInt32(&active, -1)
+			if n > limit {
+				return fmt.Errorf("saw %d active goroutines; want ≤ %d", n, limit)
+			}
+			time.Sleep(time.Microsecond)
+			return nil
+		})
+	}
+	g.Go(func() error { panic("errgroup_test: panic") })
+	g.Go(func() error { return errDoom })
+
+	err := g.Wait()
+
+	var pe *errgroup.PanicError
+	if !errors.As(err, &pe) {
+		t.Errorf("g.Wait() = %v; want it to contain a *PanicError", err)
+	}
+	if !errors.Is(err, errDoom) {
+		t.Errorf("g.Wait() = %v; want it to contain %v", err, errDoom)
+	}
+	if n := len(g.Errors()); n != 2 {
+		t.Errorf("len(g.Errors()) = %d; want 2", n)
+	}
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Errorf("ctx.Done() was not closed by Wait")
+	}
+}
+
+func TestNewNoOptions(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, ctx := errgroup.New(context.Background())
+	g.Go(func() error { return errDoom })
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+}
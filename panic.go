@@ -0,0 +1,90 @@
+package errgroup
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+)
+
+// A PanicError is the error recorded for a function passed to Go that panicked
+// while the Group was configured to recover panics.
+type PanicError struct {
+	value any
+	stack []byte
+}
+
+// Error implements the error interface.
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("errgroup: recovered panic: %v\n\n%s", p.value, p.stack)
+}
+
+// Unwrap returns the recovered value if it is an error, and nil otherwise.
+func (p *PanicError) Unwrap() error {
+	err, _ := p.value.(error)
+	return err
+}
+
+// Value returns the value passed to panic.
+func (p *PanicError) Value() any {
+	return p.value
+}
+
+// Stack returns the stack trace of the panicking goroutine, as captured by
+// debug.Stack.
+func (p *PanicError) Stack() []byte {
+	return p.stack
+}
+
+// RecoverPanics configures whether the Group recovers panics in functions
+// passed to Go. When enabled, a panic is converted into a *PanicError and
+// handled like any other error returned by the function. By default, panics
+// are not recovered and crash the program.
+func (g *Group) RecoverPanics(enable bool) {
+	g.recoverPanic = enable
+}
+
+// SetPanicHandler makes the Group recover panics in functions passed to Go, as
+// RecoverPanics(true) does, and call fn with the recovered value and the stack
+// trace of the panicking goroutine instead of converting the panic into a
+// *PanicError. The error fn returns is handled like any other error returned by
+// the function; a nil error swallows the panic, as if the function had
+// succeeded. fn is called in the panicking goroutine, so it may report the
+// panic before deciding. A nil fn restores the default conversion.
+func (g *Group) SetPanicHandler(fn func(recovered any, stack []byte) error) {
+	g.panicHandler = fn
+	if fn != nil {
+		g.recoverPanic = true
+	}
+}
+
+func (g *Group) call(f func() error) (err error) {
+	if g.recoverPanic {
+		defer func() {
+			if r := recover(); r != nil {
+				stack := debug.Stack()
+				if g.panicHandler != nil {
+					err = g.panicHandler(r, stack)
+					return
+				}
+
+				err = &PanicError{value: r, stack: stack}
+			}
+		}()
+	}
+
+	return f()
+}
+
+// captureStack returns the stack of the calling goroutine, preceded by a line
+// naming the function src and the error it returned.
+func captureStack(src any, err error) []byte {
+	name := "unknown function"
+	if fn := runtime.FuncForPC(reflect.ValueOf(src).Pointer()); fn != nil {
+		name = fn.Name()
+	}
+
+	stack := []byte(fmt.Sprintf("%s returned: %v\n\n", name, err))
+
+	return append(stack, debug.Stack()...)
+}
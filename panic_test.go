@@ -0,0 +1,218 @@
+package errgroup_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestRecoverPanics(t *testing.T) {
+	errPanic := errors.New("errgroup_test: panic")
+
+	cases := []struct {
+		value any
+	}{
+		{value: errPanic},
+		{value: "errgroup_test: panic"},
+	}
+
+	for _, tc := range cases {
+		g := new(errgroup.Group)
+		g.RecoverPanics(true)
+
+		g.Go(func() error { panic(tc.value) })
+
+		err := g.Wait()
+		if err == nil {
+			t.Fatalf("g.Wait() = nil; want a *PanicError")
+		}
+
+		var pe *errgroup.PanicError
+		if !errors.As(err, &pe) {
+			t.Fatalf("g.Wait() = %v; want a *PanicError", err)
+		}
+
+		if pe.Value() != tc.value {
+			t.Errorf("pe.Value() = %v; want %v", pe.Value(), tc.value)
+		}
+
+		if !bytes.Contains(pe.Stack(), []byte("panic_test.go")) {
+			t.Errorf("pe.Stack() = %s; want it to reference panic_test.go", pe.Stack())
+		}
+
+		if _, ok := tc.value.(error); ok && !errors.Is(err, errPanic) {
+			t.Errorf("errors.Is(%v, %v) = false; want true", err, errPanic)
+		}
+	}
+}
+
+func TestFinallyPanic(t *testing.T) {
+	for _, recoverPanics := range []bool{false, true} {
+		g := new(errgroup.Group)
+		g.RecoverPanics(recoverPanics)
+
+		calls := 0
+		g.Finally(func() error {
+			calls++
+			return nil
+		})
+		g.Finally(func() error { panic("errgroup_test: panic") })
+
+		g.Go(func() error { return nil })
+
+		var err error
+		r := func() (r any) {
+			defer func() { r = recover() }()
+			err = g.Wait()
+			return nil
+		}()
+
+		if calls != 1 {
+			t.Errorf("RecoverPanics(%t): Finally callback ran %d times; want 1", recoverPanics, calls)
+		}
+
+		var pe *errgroup.PanicError
+		if recoverPanics {
+			if !errors.As(err, &pe) {
+				t.Errorf("RecoverPanics(%t): g.Wait() = %v; want a *PanicError", recoverPanics, err)
+			}
+		} else if r == nil {
+			t.Errorf("RecoverPanics(%t): g.Wait() did not panic", recoverPanics)
+		}
+	}
+}
+
+func TestFinallyPanicState(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, ctx := errgroup.WithContext(context.Background())
+	g.Finally(func() error { panic("errgroup_test: panic") })
+	g.Go(func() error { return errDoom })
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("g.Wait() did not panic")
+			}
+		}()
+		g.Wait()
+	}()
+
+	if err := g.Wait(); !errors.Is(err, errDoom) {
+		t.Errorf("g.Wait() after the panic = %v; want it to match %v", err, errDoom)
+	}
+	select {
+	case <-g.Done():
+	default:
+		t.Errorf("g.Done() is not closed after the panic")
+	}
+	if ctx.Err() == nil {
+		t.Errorf("the group's Context is not canceled after the panic")
+	}
+	if g.Succeeded() {
+		t.Errorf("g.Succeeded() after the panic = true; want false")
+	}
+}
+
+func TestFirstErrorStack(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g := new(errgroup.Group)
+	if stack := g.FirstErrorStack(); stack != nil {
+		t.Errorf("g.FirstErrorStack() = %s before Go; want nil", stack)
+	}
+
+	g.Go(failingTask(errDoom))
+	g.Wait()
+
+	stack := g.FirstErrorStack()
+	if len(stack) == 0 {
+		t.Fatalf("g.FirstErrorStack() is empty")
+	}
+	if !bytes.Contains(stack, []byte("failingTask")) {
+		t.Errorf("g.FirstErrorStack() = %s; want it to reference failingTask", stack)
+	}
+}
+
+func TestFirstErrorStackNamesFunction(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	for _, tc := range []struct {
+		name string
+		run  func(g *errgroup.Group)
+	}{
+		{"GoCtx", func(g *errgroup.Group) { g.GoCtx(failingCtxTask(errDoom)) }},
+		{"GoTimeout", func(g *errgroup.Group) { g.GoTimeout(time.Minute, failingCtxTask(errDoom)) }},
+		{"GoRetry", func(g *errgroup.Group) { g.GoRetry(1, 0, failingTask(errDoom)) }},
+		{"GoWithResult", func(g *errgroup.Group) {
+			errgroup.GoWithResult(g, func() (int, error) { return 0, errDoom })
+		}},
+		{"Middleware", func(g *errgroup.Group) {
+			g.SetGoMiddleware(func(next func() error) func() error {
+				return func() error { return next() }
+			})
+			g.Go(failingTask(errDoom))
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := new(errgroup.Group)
+			tc.run(g)
+			g.Wait()
+
+			stack := g.FirstErrorStack()
+			line, _, _ := bytes.Cut(stack, []byte("\n"))
+			if bytes.Contains(line, []byte("errgroup.(*Group)")) || bytes.Contains(line, []byte("errgroup.GoWithResult")) {
+				t.Errorf("g.FirstErrorStack() names %s; want the function passed to %s", line, tc.name)
+			}
+			if !bytes.Contains(line, []byte("errgroup_test.")) {
+				t.Errorf("g.FirstErrorStack() names %s; want a function in errgroup_test", line)
+			}
+		})
+	}
+}
+
+func failingTask(err error) func() error {
+	return func() error { return err }
+}
+
+func TestSetPanicHandler(t *testing.T) {
+	errReported := errors.New("errgroup_test: reported panic")
+
+	for _, swallow := range []bool{true, false} {
+		g := new(errgroup.Group)
+
+		var got any
+		var stack []byte
+		g.SetPanicHandler(func(recovered any, s []byte) error {
+			got, stack = recovered, s
+			if swallow {
+				return nil
+			}
+			return errReported
+		})
+
+		g.Go(func() error { panic("errgroup_test: panic") })
+		err := g.Wait()
+
+		if swallow && err != nil {
+			t.Errorf("g.Wait() with a swallowing handler = %v; want nil", err)
+		}
+		if !swallow && err != errReported {
+			t.Errorf("g.Wait() = %v; want %v", err, errReported)
+		}
+		if got != "errgroup_test: panic" {
+			t.Errorf("handler got %v; want the panic value", got)
+		}
+		if !bytes.Contains(stack, []byte("panic_test.go")) {
+			t.Errorf("handler stack does not mention the panicking function:\n%s", stack)
+		}
+	}
+}
+
+func failingCtxTask(err error) func(ctx context.Context) error {
+	return func(ctx context.Context) error { return err }
+}
@@ -0,0 +1,87 @@
+package errgroup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SetRate limits how fast the group starts functions to at most perSecond per
+// second. Go and its variants wait for the limiter before starting a
+// function, and TryGo returns false if it is not ready. A function skipped
+// because the group's Context was canceled while waiting does not use up the
+// rate; Go then returns without calling it and records the Context's error. A
+// zero or negative perSecond, the default, removes the limit.
+//
+// The rate must not be modified while any goroutines in the group are active.
+func (g *Group) SetRate(perSecond float64) {
+	if perSecond <= 0 {
+		g.rate = nil
+		return
+	}
+
+	g.rate = &limiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// throttle waits until the group's rate allows starting a function. If the
+// group's Context is done first, it records the Context's error and returns
+// it.
+func (g *Group) throttle() error {
+	if g.rate == nil {
+		return nil
+	}
+
+	ctx := g.context()
+	if err := g.rate.wait(ctx, g.clock()); err != nil {
+		g.record(err, nil)
+		return err
+	}
+
+	return nil
+}
+
+// limiter lets one event happen per interval, with no bursts.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// allow reports whether an event may happen now, and if so accounts for it.
+func (l *limiter) allow(c clock) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := c.Now()
+	if now.Before(l.next) {
+		return false
+	}
+
+	l.next = now.Add(l.interval)
+
+	return true
+}
+
+// wait blocks until an event may happen and accounts for it, or returns
+// ctx.Err() without doing so if ctx is done first.
+func (l *limiter) wait(ctx context.Context, c clock) error {
+	for {
+		l.mu.Lock()
+		now := c.Now()
+		if !now.Before(l.next) {
+			l.next = now.Add(l.interval)
+			l.mu.Unlock()
+			return nil
+		}
+		delay := l.next.Sub(now)
+		l.mu.Unlock()
+
+		t := c.NewTimer(delay)
+		select {
+		case <-t.C():
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
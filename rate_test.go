@@ -0,0 +1,116 @@
+package errgroup_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestSetRate(t *testing.T) {
+	g := new(errgroup.Group)
+	g.SetRate(5)
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		g.Go(func() error { return nil })
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("g.Wait() = %v; want nil", err)
+	}
+
+	// The first function starts at once and each of the others 200ms after
+	// the one before it.
+	if elapsed := time.Since(start); elapsed < 1700*time.Millisecond || elapsed > 3*time.Second {
+		t.Errorf("starting 10 functions at 5/s took %v; want about 1.8s", elapsed)
+	}
+}
+
+func TestSetRateCanceled(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, _ := errgroup.WithContext(context.Background())
+	g.SetRate(0.1)
+
+	g.Go(func() error {
+		time.Sleep(10 * time.Millisecond)
+		return errDoom
+	})
+
+	var ran atomic.Bool
+	returned := make(chan struct{})
+	go func() {
+		g.Go(func() error {
+			ran.Store(true)
+			return nil
+		})
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatalf("Go kept waiting for the rate after the group was canceled")
+	}
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+	if ran.Load() {
+		t.Errorf("throttled function ran after the group was canceled")
+	}
+}
+
+func TestSetRateTryGo(t *testing.T) {
+	g := new(errgroup.Group)
+	g.SetRate(0.1)
+
+	if !g.TryGo(func() error { return nil }) {
+		t.Errorf("first g.TryGo() = false; want true")
+	}
+	if g.TryGo(func() error { return nil }) {
+		t.Errorf("second g.TryGo() within the rate = true; want false")
+	}
+	g.Wait()
+}
+
+func TestSetRateSkippedOnLimit(t *testing.T) {
+	clock := errgroup.NewFakeClock()
+	g, _ := errgroup.WithContext(context.Background())
+	errgroup.SetClock(g, clock)
+	g.SetLimit(1)
+	g.SetRate(1)
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release
+		return nil
+	})
+	clock.Advance(time.Second)
+
+	// This call waits for the slot held above and is skipped by Cancel.
+	returned := make(chan struct{})
+	go func() {
+		g.Go(func() error { return nil })
+		close(returned)
+	}()
+	for g.Pending() == 0 {
+		runtime.Gosched()
+	}
+	g.Cancel()
+	<-returned
+
+	close(release)
+	for g.Active() != 0 {
+		runtime.Gosched()
+	}
+
+	if !g.TryGo(func() error { return nil }) {
+		t.Errorf("g.TryGo() = false; want the skipped function to have left the rate unused")
+	}
+	g.Wait()
+}
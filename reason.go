@@ -0,0 +1,134 @@
+package errgroup
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+// A ReasonKind identifies what canceled a Group's Context.
+type ReasonKind int
+
+const (
+	// ReasonNone means the group has not been canceled, or has no Context.
+	ReasonNone ReasonKind = iota
+
+	// ReasonCompleted means the group was canceled because Wait returned
+	// after all its functions had, with nothing canceling it before.
+	ReasonCompleted
+
+	// ReasonTaskError means a function passed to Go returned an error.
+	ReasonTaskError
+
+	// ReasonFirstSuccess means a function succeeded in a Group created with
+	// WithFirstSuccess.
+	ReasonFirstSuccess
+
+	// ReasonSignal means one of the signals the group catches was caught.
+	ReasonSignal
+
+	// ReasonMaxLifetime means the group's maximum lifetime elapsed.
+	ReasonMaxLifetime
+
+	// ReasonDeadline means the parent Context's deadline passed.
+	ReasonDeadline
+
+	// ReasonParentCanceled means the parent Context was canceled.
+	ReasonParentCanceled
+
+	// ReasonCanceled means Cancel was called.
+	ReasonCanceled
+)
+
+var reasonNames = [...]string{
+	ReasonNone:           "none",
+	ReasonCompleted:      "completed",
+	ReasonTaskError:      "task error",
+	ReasonFirstSuccess:   "first success",
+	ReasonSignal:         "signal",
+	ReasonMaxLifetime:    "max lifetime",
+	ReasonDeadline:       "deadline",
+	ReasonParentCanceled: "parent canceled",
+	ReasonCanceled:       "canceled",
+}
+
+// String returns a short description of the kind, for logging.
+func (k ReasonKind) String() string {
+	if k < 0 || int(k) >= len(reasonNames) {
+		return "unknown"
+	}
+
+	return reasonNames[k]
+}
+
+// A Reason describes why a Group's Context was canceled.
+type Reason struct {
+	// Kind identifies what canceled the group.
+	Kind ReasonKind
+
+	// Err is the error that canceled the group: the function's error, the
+	// *SignalError, ErrMaxLifetimeExceeded, ErrCanceled, or the parent
+	// Context's cause.
+	// It is nil for ReasonNone, ReasonCompleted, and ReasonFirstSuccess.
+	Err error
+
+	// Signal is the caught signal for ReasonSignal, and nil otherwise.
+	Signal os.Signal
+}
+
+// CancelReason reports why the group's Context was canceled, as decided the
+// first time it was canceled, consolidating the causes otherwise reported by
+// context.Cause, CaughtSignal, and Wait.
+func (g *Group) CancelReason() Reason {
+	if r := g.reason.Load(); r != nil {
+		return *r
+	}
+
+	if g.ctx != nil && g.ctx.Err() != nil {
+		// The parent was canceled; nothing else has canceled the group.
+		g.setReason(nil)
+		return *g.reason.Load()
+	}
+
+	return Reason{}
+}
+
+// setReason records the reason for canceling the group with cause, where a nil
+// cause means Wait returned, unless a reason was already recorded. If the
+// parent Context is already done, the parent is the reason instead.
+func (g *Group) setReason(cause error) {
+	if g.reason.Load() != nil {
+		return
+	}
+
+	var r Reason
+	if g.parent != nil && g.parent.Err() != nil {
+		r = Reason{Kind: ReasonParentCanceled, Err: context.Cause(g.parent)}
+		if errors.Is(r.Err, context.DeadlineExceeded) {
+			r.Kind = ReasonDeadline
+		}
+	} else {
+		r = reasonFor(cause)
+	}
+
+	g.reason.CompareAndSwap(nil, &r)
+}
+
+func reasonFor(cause error) Reason {
+	var sigErr *SignalError
+
+	switch {
+	case cause == nil:
+		return Reason{Kind: ReasonCompleted}
+	case cause == errSucceeded:
+		return Reason{Kind: ReasonFirstSuccess}
+	case cause == ErrCanceled:
+		return Reason{Kind: ReasonCanceled, Err: cause}
+	case cause == ErrMaxLifetimeExceeded:
+		return Reason{Kind: ReasonMaxLifetime, Err: cause}
+	case errors.As(cause, &sigErr):
+		return Reason{Kind: ReasonSignal, Err: cause, Signal: sigErr.sig}
+	default:
+		return Reason{Kind: ReasonTaskError, Err: cause}
+	}
+}
@@ -0,0 +1,254 @@
+package errgroup_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestCancelReason(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	var zero errgroup.Group
+	zero.Go(func() error { return errDoom })
+	zero.Wait()
+	if r := zero.CancelReason(); r.Kind != errgroup.ReasonNone {
+		t.Errorf("zero Group CancelReason().Kind = %v; want %v", r.Kind, errgroup.ReasonNone)
+	}
+
+	g, _ := errgroup.WithContext(context.Background())
+	if r := g.CancelReason(); r.Kind != errgroup.ReasonNone {
+		t.Errorf("g.CancelReason().Kind before Wait = %v; want %v", r.Kind, errgroup.ReasonNone)
+	}
+	g.Go(func() error { return nil })
+	g.Wait()
+	if r := g.CancelReason(); r.Kind != errgroup.ReasonCompleted || r.Err != nil {
+		t.Errorf("g.CancelReason() after a clean Wait = %+v; want kind %v", r, errgroup.ReasonCompleted)
+	}
+
+	g, _ = errgroup.WithContext(context.Background())
+	g.Go(func() error { return errDoom })
+	g.Wait()
+	if r := g.CancelReason(); r.Kind != errgroup.ReasonTaskError || r.Err != errDoom {
+		t.Errorf("g.CancelReason() after a task error = %+v; want kind %v with %v", r, errgroup.ReasonTaskError, errDoom)
+	}
+
+	g, _ = errgroup.New(context.Background(), errgroup.MaxLifetime(time.Millisecond))
+	g.GoCtx(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	g.Wait()
+	if r := g.CancelReason(); r.Kind != errgroup.ReasonMaxLifetime || r.Err != errgroup.ErrMaxLifetimeExceeded {
+		t.Errorf("g.CancelReason() after the lifetime elapsed = %+v; want kind %v", r, errgroup.ReasonMaxLifetime)
+	}
+}
+
+func TestCancelReasonParent(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	g, ctx := errgroup.WithContext(parent)
+	g.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Wait()
+	if r := g.CancelReason(); r.Kind != errgroup.ReasonDeadline || !errors.Is(r.Err, context.DeadlineExceeded) {
+		t.Errorf("g.CancelReason() after the deadline = %+v; want kind %v", r, errgroup.ReasonDeadline)
+	}
+
+	parent, cancel = context.WithCancel(context.Background())
+	g, ctx = errgroup.WithContext(parent)
+	cancel()
+	<-ctx.Done()
+	if r := g.CancelReason(); r.Kind != errgroup.ReasonParentCanceled || r.Err != context.Canceled {
+		t.Errorf("g.CancelReason() after the parent was canceled = %+v; want kind %v", r, errgroup.ReasonParentCanceled)
+	}
+}
+
+func TestCancelReasonSignal(t *testing.T) {
+	g, ctx, _ := errgroup.WithSignalHandler(context.Background())
+
+	sigs := make(chan os.Signal, 1)
+	g.SetSignalSource(sigs)
+
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	sigs <- syscall.SIGTERM
+	g.Wait()
+
+	r := g.CancelReason()
+	if r.Kind != errgroup.ReasonSignal || r.Signal != syscall.SIGTERM || !errors.Is(r.Err, errgroup.ErrSignalReceived) {
+		t.Errorf("g.CancelReason() after a signal = %+v; want kind %v for %v", r, errgroup.ReasonSignal, syscall.SIGTERM)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	var zero errgroup.Group
+	zero.Cancel() // Must not panic.
+
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetLimit(1)
+
+	var causes []error
+	g.OnCancel(func(cause error) { causes = append(causes, cause) })
+
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	returned := make(chan struct{})
+	go func() {
+		g.Go(func() error { return nil })
+		close(returned)
+	}()
+
+	for g.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	g.Cancel()
+	g.Cancel()
+	<-returned
+
+	if err := g.Wait(); err != context.Canceled {
+		t.Errorf("g.Wait() = %v; want %v recorded by the blocked Go", err, context.Canceled)
+	}
+	if cause := context.Cause(ctx); cause != errgroup.ErrCanceled {
+		t.Errorf("context.Cause(ctx) = %v; want %v", cause, errgroup.ErrCanceled)
+	}
+	if r := g.CancelReason(); r.Kind != errgroup.ReasonCanceled || r.Err != errgroup.ErrCanceled {
+		t.Errorf("g.CancelReason() = %+v; want kind %v", r, errgroup.ReasonCanceled)
+	}
+	if len(causes) != 1 || causes[0] != errgroup.ErrCanceled {
+		t.Errorf("OnCancel callbacks got %v; want [%v]", causes, errgroup.ErrCanceled)
+	}
+}
+
+func TestAfterCancel(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	triggers := []struct {
+		name string
+		run  func(g *errgroup.Group, cancelParent context.CancelFunc)
+	}{
+		{name: "error", run: func(g *errgroup.Group, _ context.CancelFunc) {
+			g.Go(func() error { return errDoom })
+		}},
+		{name: "Cancel", run: func(g *errgroup.Group, _ context.CancelFunc) {
+			g.Cancel()
+		}},
+		{name: "parent", run: func(_ *errgroup.Group, cancelParent context.CancelFunc) {
+			cancelParent()
+		}},
+		{name: "none"},
+	}
+
+	for _, tc := range triggers {
+		parent, cancel := context.WithCancel(context.Background())
+
+		g, ctx := errgroup.WithContext(parent)
+
+		var calls atomic.Int32
+		var sawCanceled atomic.Bool
+		g.AfterCancel(func() {
+			calls.Add(1)
+			sawCanceled.Store(ctx.Err() != nil)
+		})
+
+		if tc.run != nil {
+			g.GoCtx(func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			})
+			tc.run(g, cancel)
+		} else {
+			g.Go(func() error { return nil })
+		}
+
+		g.Wait()
+		cancel()
+
+		want := int32(1)
+		if tc.run == nil {
+			want = 0
+		}
+		if n := calls.Load(); n != want {
+			t.Errorf("%s: AfterCancel callback ran %d times before Wait returned; want %d", tc.name, n, want)
+		}
+		if want == 1 && !sawCanceled.Load() {
+			t.Errorf("%s: AfterCancel callback ran before the Context was canceled", tc.name)
+		}
+	}
+}
+
+func TestAfterCancelDoesNotBlockTasks(t *testing.T) {
+	g, ctx := errgroup.WithContext(context.Background())
+
+	release := make(chan struct{})
+	g.AfterCancel(func() { <-release })
+
+	g.GoCtx(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	waited := make(chan struct{})
+	go func() {
+		g.Wait()
+		close(waited)
+	}()
+
+	g.Cancel()
+	<-ctx.Done()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for g.Active() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := g.Active(); n != 0 {
+		t.Errorf("g.Active() = %d while the AfterCancel callback blocks; want 0", n)
+	}
+
+	select {
+	case <-waited:
+		t.Fatalf("Wait returned before the AfterCancel callback did")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	<-waited
+}
+
+func TestAfterCancelSignal(t *testing.T) {
+	g, ctx, _ := errgroup.WithSignalHandler(context.Background())
+
+	sigs := make(chan os.Signal, 1)
+	g.SetSignalSource(sigs)
+
+	var calls atomic.Int32
+	g.AfterCancel(func() { calls.Add(1) })
+
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	sigs <- syscall.SIGTERM
+	g.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("AfterCancel callback ran %d times after a signal; want 1", n)
+	}
+}
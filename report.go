@@ -0,0 +1,102 @@
+package errgroup
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// A RunReport describes a run of a Group, in a form that marshals cleanly to
+// JSON. Durations are in nanoseconds.
+type RunReport struct {
+	// Started, Succeeded, and Failed count the functions that started,
+	// returned nil, and returned an error, as in GroupStats.
+	Started   int `json:"started"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+
+	// TotalDuration and MaxDuration are as in GroupStats.
+	TotalDuration time.Duration `json:"total_duration"`
+	MaxDuration   time.Duration `json:"max_duration"`
+
+	// Tasks describes each function that returned, in submission order, if
+	// EnableTaskReports was called, and is empty otherwise.
+	Tasks []TaskReport `json:"tasks"`
+
+	// CancelReason names the kind of CancelReason, and CancelError holds the
+	// message of its error, if any.
+	CancelReason string `json:"cancel_reason"`
+	CancelError  string `json:"cancel_error,omitempty"`
+
+	// Error is the message of the error Wait returns, once Wait has
+	// returned, and empty otherwise or if there is none.
+	Error string `json:"error,omitempty"`
+}
+
+// A TaskReport describes a function run by a Group.
+type TaskReport struct {
+	// Index is the position of the function in submission order.
+	Index int64 `json:"index"`
+
+	// Name is the name given to GoNamed, if any.
+	Name string `json:"name,omitempty"`
+
+	// Duration is how long the function ran.
+	Duration time.Duration `json:"duration"`
+
+	// Error is the message of the error the function returned, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// EnableTaskReports has the group keep a TaskReport for each function that
+// returns, for Report to include. Without it, which is the default, the
+// group keeps only the counts, so that its memory does not grow with the
+// number of functions run. Functions that return before EnableTaskReports is
+// called are not reported.
+func (g *Group) EnableTaskReports() {
+	g.stats.keepTasks.Store(true)
+}
+
+// Report returns a report of the group's run so far. Functions are added to it
+// as they return, and the error Wait returns once Wait has; it is meant to be
+// called after Wait.
+func (g *Group) Report() RunReport {
+	st := g.stats.snapshot()
+
+	g.stats.mu.Lock()
+	tasks := slices.Clone(g.stats.tasks)
+	g.stats.mu.Unlock()
+
+	slices.SortFunc(tasks, func(a, b TaskReport) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+	if tasks == nil {
+		tasks = []TaskReport{}
+	}
+
+	reason := g.CancelReason()
+	r := RunReport{
+		Started:       st.Started,
+		Succeeded:     st.Succeeded,
+		Failed:        st.Failed,
+		TotalDuration: st.TotalDuration,
+		MaxDuration:   st.MaxDuration,
+		Tasks:         tasks,
+		CancelReason:  reason.Kind.String(),
+	}
+	if reason.Err != nil {
+		r.CancelError = reason.Err.Error()
+	}
+
+	g.mu.Lock()
+	waited := g.waited
+	g.mu.Unlock()
+
+	if waited {
+		if err := g.waitResult(); err != nil {
+			r.Error = err.Error()
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,80 @@
+package errgroup_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestReport(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, _ := errgroup.New(context.Background(), errgroup.NoCancelOnError(), errgroup.TaskReports())
+	g.GoNamed("fetch", func() error { return nil })
+	g.GoNamed("parse", func() error { return errDoom })
+	g.Go(func() error { return nil })
+	g.Wait()
+
+	data, err := json.Marshal(g.Report())
+	if err != nil {
+		t.Fatalf("json.Marshal(g.Report()) = %v", err)
+	}
+
+	var got struct {
+		Started      int    `json:"started"`
+		Succeeded    int    `json:"succeeded"`
+		Failed       int    `json:"failed"`
+		CancelReason string `json:"cancel_reason"`
+		Error        string `json:"error"`
+		Tasks        []struct {
+			Index int64  `json:"index"`
+			Name  string `json:"name"`
+			Error string `json:"error"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal(%s) = %v", data, err)
+	}
+
+	if got.Started != 3 || got.Succeeded != 2 || got.Failed != 1 {
+		t.Errorf("report counts = %d started, %d succeeded, %d failed; want 3, 2, 1", got.Started, got.Succeeded, got.Failed)
+	}
+	if got.CancelReason != "completed" {
+		t.Errorf("report cancel_reason = %q; want %q", got.CancelReason, "completed")
+	}
+	if want := `task "parse": group_test: doomed`; got.Error != want {
+		t.Errorf("report error = %q; want %q", got.Error, want)
+	}
+
+	if len(got.Tasks) != 3 {
+		t.Fatalf("report has %d tasks; want 3:\n%s", len(got.Tasks), data)
+	}
+	for i, want := range []struct{ name, err string }{
+		{name: "fetch"},
+		{name: "parse", err: `task "parse": group_test: doomed`},
+		{},
+	} {
+		task := got.Tasks[i]
+		if task.Index != int64(i) || task.Name != want.name || task.Error != want.err {
+			t.Errorf("report task %d = %+v; want index %d, name %q, error %q", i, task, i, want.name, want.err)
+		}
+	}
+}
+
+func TestReportNoTasks(t *testing.T) {
+	g, _ := errgroup.New(context.Background(), errgroup.NoCancelOnError())
+	g.GoNamed("fetch", func() error { return nil })
+	g.GoNamed("parse", func() error { return errors.New("group_test: doomed") })
+	g.Wait()
+
+	r := g.Report()
+	if r.Started != 2 || r.Failed != 1 {
+		t.Errorf("report counts = %d started, %d failed; want 2, 1", r.Started, r.Failed)
+	}
+	if len(r.Tasks) != 0 {
+		t.Errorf("report has %d tasks without EnableTaskReports; want 0", len(r.Tasks))
+	}
+}
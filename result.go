@@ -0,0 +1,197 @@
+package errgroup
+
+import (
+	"context"
+	"sync"
+)
+
+// A ResultGroup is a Group whose functions each return a value alongside their
+// error. Wait returns the values of the functions that succeeded.
+//
+// A zero ResultGroup is valid and does not cancel on error.
+type ResultGroup[T any] struct {
+	Group
+
+	mu      sync.Mutex
+	results []T
+	ok      []bool
+	stream  chan T
+}
+
+// NewResultGroup returns a new ResultGroup and an associated Context derived
+// from ctx.
+//
+// The derived Context is canceled the same way as one returned by WithContext.
+func NewResultGroup[T any](ctx context.Context) (*ResultGroup[T], context.Context) {
+	r := new(ResultGroup[T])
+	r.Group.withCancel(ctx)
+
+	return r, r.Group.ctx
+}
+
+// Go calls the given function in a new goroutine, as Group.Go does, and
+// records the value it returns if its error is nil, also sending it on the
+// channel returned by Stream, if any.
+func (r *ResultGroup[T]) Go(f func() (T, error)) {
+	if f == nil {
+		panicNilFunc("Go")
+	}
+
+	r.mu.Lock()
+	i := len(r.results)
+	r.results = append(r.results, *new(T))
+	r.ok = append(r.ok, false)
+	r.mu.Unlock()
+
+	r.Group.goWrapped(f, func() error {
+		v, err := f()
+		if err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		r.results[i] = v
+		r.ok[i] = true
+		stream := r.stream
+		r.mu.Unlock()
+
+		if stream == nil {
+			return nil
+		}
+
+		// Prefer dropping the value once the group is done over a racing send.
+		done := r.Group.context().Done()
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+
+		select {
+		case stream <- v:
+		case <-done:
+		}
+
+		return nil
+	})
+}
+
+// Stream returns a channel on which the values of the functions passed to Go
+// afterwards are sent as each succeeds, in the order in which they complete,
+// for callers that process results progressively instead of waiting for all
+// of them. The channel is closed once the group has been waited for, whether
+// by Wait, Close, WaitContext, or WaitAll, which suits receiving from it in
+// one goroutine while calling Wait in another; Wait still returns every value.
+// Sends block the sending function until the value is received, so the caller
+// must keep receiving until the channel is closed or the group's Context is
+// done, at which point pending values are no longer sent. Successive calls
+// return the same channel until the group has been waited for, and a closed
+// channel afterwards.
+func (r *ResultGroup[T]) Stream() <-chan T {
+	r.mu.Lock()
+	stream := r.stream
+	created := stream == nil
+	if created {
+		stream = make(chan T)
+		r.stream = stream
+	}
+	r.mu.Unlock()
+
+	if created {
+		r.Group.afterWait(r.closeStream)
+	}
+
+	return stream
+}
+
+// closeStream closes the channel returned by Stream, if any.
+func (r *ResultGroup[T]) closeStream() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stream != nil {
+		close(r.stream)
+		r.stream = nil
+	}
+}
+
+// Reset prepares the group for reuse after Wait has returned, as Group.Reset
+// does, also discarding the values recorded by the previous round.
+func (r *ResultGroup[T]) Reset() {
+	r.Group.Reset()
+
+	r.mu.Lock()
+	r.results = nil
+	r.ok = nil
+	r.stream = nil
+	r.mu.Unlock()
+}
+
+// Wait blocks until all function calls from the Go method have returned, then
+// returns the values of those that succeeded, in the order the functions were
+// submitted, along with the error Group.Wait returns.
+//
+// If the group's Context is canceled partway, by an error or by its parent,
+// the values of the functions that had already succeeded, or that succeed
+// despite the cancellation, are still returned alongside the error. Functions
+// that were never called because of the cancellation contribute no value.
+func (r *ResultGroup[T]) Wait() ([]T, error) {
+	err := r.Group.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]T, 0, len(r.results))
+	for i, v := range r.results {
+		if r.ok[i] {
+			results = append(results, v)
+		}
+	}
+
+	return results, err
+}
+
+// A Future holds the result of a single function started with GoWithResult.
+type Future[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// GoWithResult calls the given function in a new goroutine of g, as g.Go does,
+// and returns a Future for its result. The function's error is recorded by the
+// group as well as being returned by Get; if the function panics and the group
+// recovers it, Get returns the resulting error. If the group drops the
+// function without calling it, say because its Context was canceled while
+// waiting for a slot or because Wait has returned, Get returns the error
+// recorded in its place.
+func GoWithResult[T any](g *Group, f func() (T, error)) *Future[T] {
+	if f == nil {
+		panicNilFunc("GoWithResult")
+	}
+
+	fut := &Future[T]{done: make(chan struct{})}
+
+	// The Future takes its error from the group rather than from f, so that
+	// a recovered panic, or an error replaced by middleware, is seen by Get.
+	finish := func(err error) {
+		fut.err = err
+		close(fut.done)
+	}
+	g.submit("", f, func() error {
+		v, err := f()
+		fut.value = v
+
+		return err
+	}, finish, finish)
+
+	return fut
+}
+
+// Get blocks until the function has returned, or was dropped by the group,
+// then returns its value and error. Get may be called any number of times
+// from any goroutine.
+func (fut *Future[T]) Get() (T, error) {
+	<-fut.done
+	return fut.value, fut.err
+}
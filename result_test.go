@@ -0,0 +1,380 @@
+package errgroup_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestResultGroup(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	r, _ := errgroup.NewResultGroup[int](context.Background())
+	for i := 0; i < 5; i++ {
+		r.Go(func() (int, error) {
+			// Finish in reverse order of submission.
+			time.Sleep(time.Duration(5-i) * time.Millisecond)
+			if i == 2 {
+				return 0, errDoom
+			}
+			return i, nil
+		})
+	}
+
+	results, err := r.Wait()
+	if err != errDoom {
+		t.Errorf("r.Wait() error = %v; want %v", err, errDoom)
+	}
+
+	if want := []int{0, 1, 3, 4}; fmt.Sprint(results) != fmt.Sprint(want) {
+		t.Errorf("r.Wait() results = %v; want %v", results, want)
+	}
+}
+
+func TestResultGroupStream(t *testing.T) {
+	const n = 4
+
+	r, _ := errgroup.NewResultGroup[int](context.Background())
+	stream := r.Stream()
+
+	release := make([]chan struct{}, n)
+	for i := range release {
+		release[i] = make(chan struct{})
+		r.Go(func() (int, error) {
+			<-release[i]
+			return i, nil
+		})
+	}
+
+	waited := make(chan []int, 1)
+	go func() {
+		results, _ := r.Wait()
+		waited <- results
+	}()
+
+	// Each value arrives as soon as its function returns, well before Wait.
+	for i := n - 1; i >= 0; i-- {
+		close(release[i])
+		select {
+		case v := <-stream:
+			if v != i {
+				t.Errorf("received %d; want %d", v, i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("value %d was not streamed", i)
+		}
+	}
+
+	select {
+	case v, ok := <-stream:
+		if ok {
+			t.Errorf("received %d after the last function; want the channel closed", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("the stream was not closed after the last function")
+	}
+
+	if results := <-waited; len(results) != n {
+		t.Errorf("r.Wait() results = %v; want %d values", results, n)
+	}
+}
+
+func TestResultGroupStreamError(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	r, ctx := errgroup.NewResultGroup[string](context.Background())
+	stream := r.Stream()
+
+	r.Go(func() (string, error) { return "", errDoom })
+	r.Go(func() (string, error) {
+		<-ctx.Done()
+		return "late", nil
+	})
+
+	var err error
+	done := make(chan struct{})
+	go func() {
+		_, err = r.Wait()
+		close(done)
+	}()
+
+	// The error cancels the group, which stops values from being sent.
+	for v := range stream {
+		t.Errorf("received %q; want nothing streamed after the error", v)
+	}
+	<-done
+
+	if err != errDoom {
+		t.Errorf("r.Wait() error = %v; want %v", err, errDoom)
+	}
+}
+
+func TestResultGroupStreamClosed(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		wait func(r *errgroup.ResultGroup[int])
+	}{
+		{"Wait", func(r *errgroup.ResultGroup[int]) { r.Wait() }},
+		{"Close", func(r *errgroup.ResultGroup[int]) { r.Close() }},
+		{"WaitContext", func(r *errgroup.ResultGroup[int]) { r.WaitContext(context.Background()) }},
+		{"WaitAll", func(r *errgroup.ResultGroup[int]) { r.WaitAll() }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, _ := errgroup.NewResultGroup[int](context.Background())
+			stream := r.Stream()
+			r.Go(func() (int, error) { return 0, nil })
+
+			go tc.wait(r)
+
+			timeout := time.After(time.Second)
+			for {
+				select {
+				case _, ok := <-stream:
+					if !ok {
+						return
+					}
+				case <-timeout:
+					t.Fatalf("the stream was not closed after %s", tc.name)
+				}
+			}
+		})
+	}
+
+	r, _ := errgroup.NewResultGroup[int](context.Background())
+	r.Wait()
+	if _, ok := <-r.Stream(); ok {
+		t.Errorf("received from the stream after Wait; want it closed")
+	}
+}
+
+func TestResultGroupReset(t *testing.T) {
+	r, _ := errgroup.NewResultGroup[int](context.Background())
+	r.Go(func() (int, error) { return 1, nil })
+	r.Wait()
+
+	r.Reset()
+	r.Go(func() (int, error) { return 2, nil })
+
+	results, err := r.Wait()
+	if err != nil {
+		t.Errorf("r.Wait() error = %v; want nil", err)
+	}
+	if want := []int{2}; fmt.Sprint(results) != fmt.Sprint(want) {
+		t.Errorf("r.Wait() results after Reset = %v; want %v", results, want)
+	}
+}
+
+func TestZeroResultGroup(t *testing.T) {
+	var r errgroup.ResultGroup[string]
+	r.SetLimit(1)
+	r.Go(func() (string, error) { return "a", nil })
+	r.Go(func() (string, error) { return "b", nil })
+
+	results, err := r.Wait()
+	if err != nil {
+		t.Errorf("r.Wait() error = %v; want nil", err)
+	}
+
+	if want := []string{"a", "b"}; fmt.Sprint(results) != fmt.Sprint(want) {
+		t.Errorf("r.Wait() results = %v; want %v", results, want)
+	}
+}
+
+func TestGoWithResult(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g := new(errgroup.Group)
+	ok := errgroup.GoWithResult(g, func() (int, error) { return 42, nil })
+	doomed := errgroup.GoWithResult(g, func() (int, error) { return 0, errDoom })
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+
+	for i := 0; i < 2; i++ {
+		if v, err := ok.Get(); v != 42 || err != nil {
+			t.Errorf("ok.Get() = %v, %v; want 42, nil", v, err)
+		}
+		if _, err := doomed.Get(); err != errDoom {
+			t.Errorf("doomed.Get() error = %v; want %v", err, errDoom)
+		}
+	}
+}
+
+func TestGoWithResultPanic(t *testing.T) {
+	g := new(errgroup.Group)
+	g.RecoverPanics(true)
+	fut := errgroup.GoWithResult(g, func() (int, error) { panic("boom") })
+
+	werr := g.Wait()
+	var pe *errgroup.PanicError
+	if !errors.As(werr, &pe) {
+		t.Fatalf("g.Wait() = %v; want a *PanicError", werr)
+	}
+
+	if v, err := fut.Get(); v != 0 || err != werr {
+		t.Errorf("fut.Get() = %v, %v; want 0, %v", v, err, werr)
+	}
+}
+
+func TestGoWithResultSkipped(t *testing.T) {
+	block := func(g *errgroup.Group) chan struct{} {
+		release := make(chan struct{})
+		g.Go(func() error {
+			<-release
+			return nil
+		})
+		return release
+	}
+
+	cases := []struct {
+		name  string
+		setup func(g *errgroup.Group) chan struct{}
+		want  error
+	}{
+		{
+			name: "Limit",
+			setup: func(g *errgroup.Group) chan struct{} {
+				g.SetLimit(1)
+				return block(g)
+			},
+			want: context.Canceled,
+		},
+		{
+			name: "Buffer",
+			setup: func(g *errgroup.Group) chan struct{} {
+				g.SetLimit(1)
+				g.SetBuffer(1)
+				return block(g)
+			},
+			want: context.Canceled,
+		},
+		{
+			name: "Rate",
+			setup: func(g *errgroup.Group) chan struct{} {
+				errgroup.SetClock(g, errgroup.NewFakeClock())
+				g.SetRate(1)
+				return block(g)
+			},
+			want: context.Canceled,
+		},
+		{
+			name: "Closed",
+			setup: func(g *errgroup.Group) chan struct{} {
+				g.Wait()
+				return nil
+			},
+			want: errgroup.ErrGroupClosed,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, _ := errgroup.WithContext(context.Background())
+			release := tc.setup(g)
+
+			submitted := make(chan *errgroup.Future[int], 1)
+			go func() {
+				submitted <- errgroup.GoWithResult(g, func() (int, error) { return 1, nil })
+			}()
+
+			var fut *errgroup.Future[int]
+			if release != nil {
+				// Let GoWithResult start waiting, then cancel it.
+				select {
+				case fut = <-submitted:
+				case <-time.After(10 * time.Millisecond):
+				}
+				g.Cancel()
+			}
+			if fut == nil {
+				fut = <-submitted
+			}
+
+			got := make(chan error, 1)
+			go func() {
+				_, err := fut.Get()
+				got <- err
+			}()
+
+			select {
+			case err := <-got:
+				if err != tc.want {
+					t.Errorf("fut.Get() error = %v; want %v", err, tc.want)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("fut.Get() blocked for a function the group dropped")
+			}
+
+			if release != nil {
+				close(release)
+				g.Wait()
+			}
+		})
+	}
+}
+
+func TestFutureGetConcurrent(t *testing.T) {
+	g := new(errgroup.Group)
+
+	release := make(chan struct{})
+	fut := errgroup.GoWithResult(g, func() (string, error) {
+		<-release
+		return "done", nil
+	})
+
+	var readers errgroup.Group
+	for i := 0; i < 10; i++ {
+		readers.Go(func() error {
+			if v, _ := fut.Get(); v != "done" {
+				return fmt.Errorf("fut.Get() = %q; want %q", v, "done")
+			}
+			return nil
+		})
+	}
+
+	close(release)
+
+	if err := readers.Wait(); err != nil {
+		t.Error(err)
+	}
+	g.Wait()
+}
+
+func TestResultGroupCanceled(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r, ctx := errgroup.NewResultGroup[int](parent)
+
+	completed := make(chan struct{}, 3)
+	for i := 0; i < 6; i++ {
+		r.Go(func() (int, error) {
+			if i%2 == 0 {
+				completed <- struct{}{}
+				return i, nil
+			}
+
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+	}
+
+	for i := 0; i < 3; i++ {
+		<-completed
+	}
+	cancel()
+
+	results, err := r.Wait()
+	if err != context.Canceled {
+		t.Errorf("r.Wait() error = %v; want %v", err, context.Canceled)
+	}
+
+	if want := []int{0, 2, 4}; fmt.Sprint(results) != fmt.Sprint(want) {
+		t.Errorf("r.Wait() results = %v; want %v", results, want)
+	}
+}
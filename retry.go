@@ -0,0 +1,57 @@
+package errgroup
+
+import (
+	"context"
+	"time"
+)
+
+// GoRetry calls the given function in a new goroutine, as Go does, calling it
+// again after backoff each time it returns an error, up to attempts calls in
+// total. Only the error from the last call is recorded. Retrying stops early,
+// recording that last error, if the group's Context is canceled while waiting
+// for the next attempt.
+func (g *Group) GoRetry(attempts int, backoff time.Duration, f func() error) {
+	if f == nil {
+		panicNilFunc("GoRetry")
+	}
+
+	ctx := g.context()
+	c := g.clock()
+	g.goWrapped(f, func() error { return retry(ctx, c, attempts, backoff, nil, f) })
+}
+
+// GoRetryIf calls the given function as GoRetry does, but only retries it if
+// retryable reports true for the error it returned; any other error is
+// recorded at once.
+func (g *Group) GoRetryIf(attempts int, backoff time.Duration, retryable func(err error) bool, f func() error) {
+	if f == nil {
+		panicNilFunc("GoRetryIf")
+	}
+
+	ctx := g.context()
+	c := g.clock()
+	g.goWrapped(f, func() error { return retry(ctx, c, attempts, backoff, retryable, f) })
+}
+
+// retry calls f up to attempts times while it returns an error that retryable,
+// if not nil, reports as worth retrying.
+func retry(ctx context.Context, c clock, attempts int, backoff time.Duration, retryable func(err error) bool, f func() error) error {
+	err := f()
+	for i := 1; i < attempts && err != nil; i++ {
+		if retryable != nil && !retryable(err) {
+			return err
+		}
+
+		t := c.NewTimer(backoff)
+		select {
+		case <-t.C():
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		}
+
+		err = f()
+	}
+
+	return err
+}
@@ -0,0 +1,97 @@
+package errgroup_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestGoRetry(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	cases := []struct {
+		failures  int
+		want      error
+		wantCalls int
+	}{
+		{failures: 2, want: nil, wantCalls: 3},
+		{failures: 5, want: errDoom, wantCalls: 3},
+	}
+
+	for _, tc := range cases {
+		g := new(errgroup.Group)
+
+		calls := 0
+		failures := tc.failures
+		g.GoRetry(3, time.Millisecond, func() error {
+			calls++
+			if calls <= failures {
+				return errDoom
+			}
+			return nil
+		})
+
+		if err := g.Wait(); err != tc.want {
+			t.Errorf("g.Wait() with %d failures = %v; want %v", tc.failures, err, tc.want)
+		}
+		if calls != tc.wantCalls {
+			t.Errorf("f called %d times with %d failures; want %d", calls, tc.failures, tc.wantCalls)
+		}
+	}
+}
+
+func TestGoRetryCanceled(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, _ := errgroup.WithContext(ctx)
+
+	calls := 0
+	g.GoRetry(3, time.Hour, func() error {
+		calls++
+		cancel()
+		return errDoom
+	})
+
+	if err := g.Wait(); err != errDoom {
+		t.Errorf("g.Wait() = %v; want %v", err, errDoom)
+	}
+	if calls != 1 {
+		t.Errorf("f called %d times; want 1", calls)
+	}
+}
+
+func TestGoRetryIf(t *testing.T) {
+	errTransient := errors.New("group_test: transient")
+	errPermanent := errors.New("group_test: permanent")
+
+	retryable := func(err error) bool { return err == errTransient }
+
+	cases := []struct {
+		err       error
+		wantCalls int
+	}{
+		{err: errTransient, wantCalls: 3},
+		{err: errPermanent, wantCalls: 1},
+	}
+
+	for _, tc := range cases {
+		g := new(errgroup.Group)
+
+		calls := 0
+		g.GoRetryIf(3, time.Millisecond, retryable, func() error {
+			calls++
+			return tc.err
+		})
+
+		if err := g.Wait(); err != tc.err {
+			t.Errorf("g.Wait() = %v; want %v", err, tc.err)
+		}
+		if calls != tc.wantCalls {
+			t.Errorf("f called %d times for %v; want %d", calls, tc.err, tc.wantCalls)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package errgroup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ErrSignalReceived is matched by the error returned from Wait when the group
+// was shut down by a caught signal.
+var ErrSignalReceived = errors.New("errgroup: signal received")
+
+// A SignalError records the signal that shut down a Group.
+type SignalError struct {
+	sig os.Signal
+}
+
+// Error implements the error interface. The message names the signal both by
+// its description and, when known, its constant, as in
+// "received signal: terminated (SIGTERM)".
+func (e *SignalError) Error() string {
+	if name, ok := signalNames[e.sig]; ok {
+		return fmt.Sprintf("received signal: %v (%s)", e.sig, name)
+	}
+
+	return fmt.Sprintf("received signal: %v", e.sig)
+}
+
+// Is reports whether target is ErrSignalReceived.
+func (e *SignalError) Is(target error) bool {
+	return target == ErrSignalReceived
+}
+
+// Signal returns the caught signal.
+func (e *SignalError) Signal() os.Signal {
+	return e.sig
+}
+
+// defaultSignals are the signals caught when none are configured. SIGKILL is
+// deliberately absent; it is never delivered to the process.
+var defaultSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// SetShutdownTimeout bounds how long the Group waits for its goroutines to
+// return after the first caught signal. If they are still running once d has
+// elapsed, the program exits with status 1. A zero or negative d, the default,
+// waits until a second signal is caught instead.
+func (g *Group) SetShutdownTimeout(d time.Duration) {
+	g.shutdown = d
+}
+
+// SetSignalSource makes the group read signals from c instead of registering
+// for them with signal.Notify, which lets a caller multiplex signals from its
+// own handler or deliver them deterministically in tests. It also enables
+// signal handling for a Group not created with WithSignalHandler. A nil c
+// goes back to signal.Notify and leaves whether signals are handled unchanged.
+func (g *Group) SetSignalSource(c <-chan os.Signal) {
+	g.sigSource = c
+	if c != nil {
+		g.catchSignals = true
+	}
+}
+
+// ForwardSignals makes the signal handler send the signals caught after the
+// first one to c instead of exiting the program, leaving it to the caller to
+// decide what to do with them. Sends to c block the handler until they are
+// received or Wait returns. The shutdown timeout, if set, still exits.
+func (g *Group) ForwardSignals(c chan<- os.Signal) {
+	g.forward = c
+}
+
+// ReraiseSignals configures whether the signal handler ends the program by
+// restoring the default behavior for the terminating signal and sending it to
+// the process again, so that the program exits as if killed by that signal,
+// for example with status 130 for SIGINT in a shell. The terminating signal is
+// the second one caught, or the first if the shutdown timeout elapses. If the
+// signal cannot be sent, the program exits as it otherwise would.
+func (g *Group) ReraiseSignals(enable bool) {
+	g.reraise = enable
+}
+
+// KeepStopOpen makes the group close its stop channel only when a signal is
+// caught, leaving it open when Wait completes without one, so that a receiver
+// on it learns specifically of a signal. By default the channel is closed in
+// either case; see WithSignalHandler.
+func (g *Group) KeepStopOpen() {
+	g.keepStopOpen = true
+}
+
+// SetExitFunc replaces the function called to exit the program from the signal
+// handler, which defaults to os.Exit. On the second caught signal the exit code
+// is 1 if a function passed to Go has returned an error and 0 otherwise.
+func (g *Group) SetExitFunc(exit func(code int)) {
+	g.exit = exit
+}
+
+// DisableExitOnError keeps the signal handler from exiting the program on the
+// second caught signal if a function passed to Go has already returned an
+// error, so that Wait returns that error joined with the *SignalError and the
+// caller can log it. The handler stops instead, restoring the default behavior
+// of the signals, so that a further one still kills a program whose functions
+// are stuck. Without an error, or once the shutdown timeout elapses, the
+// program exits as usual.
+func (g *Group) DisableExitOnError() {
+	g.noErrExit = true
+}
+
+// SetSignalHandler makes the signal handler call fn for each caught signal
+// before acting on it. If fn returns true, the signal is handled as usual: the
+// first one shuts down the group and later ones exit the program or are
+// forwarded. If it returns false, the signal is ignored, which suits signals
+// such as SIGHUP that the application handles itself, for example by
+// reloading its configuration. fn is called from the handler's goroutine and
+// should return promptly.
+//
+// The signals must still be among those the group catches; SetSignalHandler
+// has no effect on a Group that does not handle signals.
+func (g *Group) SetSignalHandler(fn func(sig os.Signal) (stop bool)) {
+	g.sigHandler = fn
+}
+
+// CaughtSignal returns the signal that shut down the group and true, or nil and
+// false if no signal was caught.
+func (g *Group) CaughtSignal() (os.Signal, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	sigErr, ok := g.sigErr.(*SignalError)
+	if !ok {
+		return nil, false
+	}
+
+	return sigErr.sig, true
+}
+
+// handleSignals starts a goroutine that, on the first caught signal, runs
+// finally, cancels the context, and closes the stop channel, then exits the
+// program on the second one, or forwards it and any later ones, or exits once
+// the shutdown timeout elapses.
+//
+// The returned function stops the handler and releases the signal
+// registration, if any; it must be called exactly once.
+func (g *Group) handleSignals() func() {
+	c := g.sigSource
+
+	var notify chan os.Signal
+	if c == nil {
+		sigs := g.signals
+		if len(sigs) == 0 {
+			// signal.Notify with no signals would relay them all, including
+			// the runtime's own, such as SIGURG.
+			sigs = defaultSignals
+		}
+
+		notify = make(chan os.Signal, 2)
+		signal.Notify(notify, sigs...)
+		c = notify
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		if notify != nil {
+			defer signal.Stop(notify)
+		}
+
+		var sig os.Signal
+		for sig == nil {
+			select {
+			case next := <-c:
+				if g.accept(next) {
+					sig = next
+				}
+			case <-done:
+				return
+			}
+		}
+
+		sigErr := &SignalError{sig: sig}
+
+		g.mu.Lock()
+		g.sigErr = sigErr
+		g.mu.Unlock()
+
+		if g.finallyOrder == FinallyAfterCancel {
+			g.abort(sigErr)
+			g.runFinally()
+		} else {
+			g.runFinally()
+			g.abort(sigErr)
+		}
+
+		g.closeStop()
+
+		var timeout <-chan time.Time
+		if g.shutdown > 0 {
+			t := g.clock().NewTimer(g.shutdown)
+			defer t.Stop()
+			timeout = t.C()
+		}
+
+		for {
+			select {
+			case next := <-c:
+				if !g.accept(next) {
+					continue
+				}
+
+				if g.forward == nil {
+					if g.failed() {
+						if g.noErrExit {
+							return
+						}
+						g.terminate(next, 1)
+					} else {
+						g.terminate(next, 0)
+					}
+					return
+				}
+
+				select {
+				case g.forward <- next:
+				case <-done:
+					return
+				}
+			case <-timeout:
+				g.terminate(sig, 1)
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// accept reports whether the handler should act on sig, as decided by the
+// function set with SetSignalHandler, if any.
+func (g *Group) accept(sig os.Signal) bool {
+	if g.sigHandler == nil {
+		return true
+	}
+
+	return g.sigHandler(sig)
+}
+
+// closeStop closes the stop channel at most once, and not at all if the caller
+// already closed it.
+func (g *Group) closeStop() {
+	g.stopOnce.Do(func() {
+		if g.stop == nil {
+			return
+		}
+
+		select {
+		case <-g.stop:
+		default:
+			close(g.stop)
+		}
+	})
+}
+
+// terminate ends the program, by re-raising sig if the group is configured to
+// and otherwise by exiting with code.
+func (g *Group) terminate(sig os.Signal, code int) {
+	if g.reraise {
+		raise := g.raise
+		if raise == nil {
+			raise = raiseSignal
+		}
+
+		if err := raise(sig); err == nil {
+			return
+		}
+	}
+
+	g.doExit(code)
+}
+
+// raiseSignal restores the default behavior for sig and sends it to the
+// current process.
+func raiseSignal(sig os.Signal) error {
+	signal.Reset(sig)
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		return err
+	}
+
+	return p.Signal(sig)
+}
+
+func (g *Group) doExit(code int) {
+	if g.exit == nil {
+		os.Exit(code)
+	}
+
+	g.exit(code)
+}
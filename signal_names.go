@@ -0,0 +1,21 @@
+//go:build !plan9 && !js
+
+package errgroup
+
+import (
+	"os"
+	"syscall"
+)
+
+// signalNames maps the common signals to the names of their constants, for
+// the platforms whose syscall package defines them all.
+var signalNames = map[os.Signal]string{
+	syscall.SIGHUP:  "SIGHUP",
+	syscall.SIGINT:  "SIGINT",
+	syscall.SIGQUIT: "SIGQUIT",
+	syscall.SIGABRT: "SIGABRT",
+	syscall.SIGKILL: "SIGKILL",
+	syscall.SIGPIPE: "SIGPIPE",
+	syscall.SIGALRM: "SIGALRM",
+	syscall.SIGTERM: "SIGTERM",
+}
@@ -0,0 +1,10 @@
+//go:build plan9 || js
+
+package errgroup
+
+import "os"
+
+// signalNames is empty where the syscall package lacks some of the common
+// signals, as on js, or where they are notes rather than signals, as on
+// plan9; a SignalError then names its signal by description only.
+var signalNames = map[os.Signal]string{}
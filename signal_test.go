@@ -0,0 +1,781 @@
+package errgroup_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestDefaultSignals(t *testing.T) {
+	want := []os.Signal{os.Interrupt, syscall.SIGTERM}
+	if !reflect.DeepEqual(errgroup.DefaultSignals, want) {
+		t.Errorf("default signals = %v; want %v", errgroup.DefaultSignals, want)
+	}
+}
+
+func TestWithSignalHandlerNoLeak(t *testing.T) {
+	run := func() {
+		g, _, _ := errgroup.WithSignalHandler(context.Background())
+		g.Go(func() error { return nil })
+		g.Wait()
+	}
+
+	// The first registration starts the os/signal watcher, which lives for the
+	// rest of the process.
+	run()
+	before := settledNumGoroutine(-1)
+
+	for i := 0; i < 10; i++ {
+		run()
+	}
+
+	if after := settledNumGoroutine(before); after > before {
+		t.Errorf("runtime.NumGoroutine() = %d after Wait; want %d", after, before)
+	}
+}
+
+// settledNumGoroutine polls runtime.NumGoroutine until it drops to want or a
+// deadline passes, returning the last observed count. A negative want waits
+// for the count to stop changing.
+func settledNumGoroutine(want int) int {
+	n := runtime.NumGoroutine()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+
+		m := runtime.NumGoroutine()
+		if (want >= 0 && m <= want) || (want < 0 && m == n) {
+			return m
+		}
+
+		n = m
+	}
+
+	return n
+}
+
+func TestWithSignalHandlerSignals(t *testing.T) {
+	cases := []struct {
+		sigs []os.Signal
+		want bool
+	}{
+		{sigs: []os.Signal{os.Interrupt, syscall.SIGHUP}, want: true},
+		{sigs: []os.Signal{os.Interrupt}, want: false},
+	}
+
+	ignoreSignal(t, syscall.SIGHUP)
+
+	for _, tc := range cases {
+		g, ctx, stop := errgroup.WithSignalHandler(context.Background(), tc.sigs...)
+
+		release := make(chan struct{})
+		g.Go(func() error {
+			select {
+			case <-ctx.Done():
+			case <-release:
+			}
+			return nil
+		})
+
+		waited := make(chan struct{})
+		go func() {
+			g.Wait()
+			close(waited)
+		}()
+
+		// Give Wait a chance to register the handler.
+		time.Sleep(100 * time.Millisecond)
+		sendSignal(t, syscall.SIGHUP)
+
+		select {
+		case <-stop:
+			if !tc.want {
+				t.Errorf("stop was closed by SIGHUP with signals %v", tc.sigs)
+			}
+		case <-time.After(500 * time.Millisecond):
+			if tc.want {
+				t.Errorf("stop was not closed by SIGHUP with signals %v", tc.sigs)
+			}
+		}
+
+		close(release)
+		<-waited
+	}
+}
+
+func sendSignal(t *testing.T, sig os.Signal) {
+	t.Helper()
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Signal(sig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetShutdownTimeout(t *testing.T) {
+	ignoreSignal(t, syscall.SIGHUP)
+
+	g, _, _ := errgroup.WithSignalHandler(context.Background(), syscall.SIGHUP)
+	g.SetShutdownTimeout(50 * time.Millisecond)
+
+	exited := make(chan int, 1)
+	g.SetExitFunc(func(code int) { exited <- code })
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release // Ignores cancellation.
+		return nil
+	})
+
+	waited := make(chan struct{})
+	go func() {
+		g.Wait()
+		close(waited)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	sendSignal(t, syscall.SIGHUP)
+
+	select {
+	case code := <-exited:
+		if code != 1 {
+			t.Errorf("exit code = %d; want 1", code)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("exit was not called after the shutdown timeout")
+	}
+
+	close(release)
+	<-waited
+}
+
+func TestSetExitFunc(t *testing.T) {
+	ignoreSignal(t, syscall.SIGHUP)
+
+	errDoom := errors.New("group_test: doomed")
+
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{err: nil, want: 0},
+		{err: errDoom, want: 1},
+	}
+
+	for _, tc := range cases {
+		g, _, _ := errgroup.WithSignalHandler(context.Background(), syscall.SIGHUP)
+
+		exited := make(chan int, 1)
+		g.SetExitFunc(func(code int) { exited <- code })
+
+		err := tc.err
+		g.Go(func() error { return err })
+
+		release := make(chan struct{})
+		g.Go(func() error {
+			<-release // Ignores cancellation.
+			return nil
+		})
+
+		waited := make(chan struct{})
+		go func() {
+			g.Wait()
+			close(waited)
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		sendSignal(t, syscall.SIGHUP)
+		time.Sleep(50 * time.Millisecond)
+		sendSignal(t, syscall.SIGHUP)
+
+		select {
+		case code := <-exited:
+			if code != tc.want {
+				t.Errorf("exit code with error %v = %d; want %d", tc.err, code, tc.want)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("exit was not called after the second signal")
+		}
+
+		close(release)
+		<-waited
+	}
+}
+
+func TestDisableExitOnError(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g, ctx := errgroup.New(context.Background(), errgroup.Signals(syscall.SIGHUP), errgroup.NoExitOnError())
+
+	sigs := make(chan os.Signal)
+	g.SetSignalSource(sigs)
+
+	exited := make(chan int, 1)
+	g.SetExitFunc(func(code int) { exited <- code })
+
+	g.Go(func() error { return errDoom })
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-ctx.Done()
+		<-release
+		return nil
+	})
+
+	waited := make(chan error, 1)
+	go func() { waited <- g.Wait() }()
+
+	for g.Active() > 1 {
+		runtime.Gosched()
+	}
+	sigs <- syscall.SIGHUP
+	sigs <- syscall.SIGHUP
+
+	// The handler stopped on the second signal rather than exiting, so nothing
+	// receives a third one.
+	select {
+	case sigs <- syscall.SIGHUP:
+		t.Errorf("the signal handler kept running after the second signal")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	err := <-waited
+
+	select {
+	case code := <-exited:
+		t.Errorf("exit called with %d; want it not called", code)
+	default:
+	}
+
+	if !errors.Is(err, errDoom) || !errors.Is(err, errgroup.ErrSignalReceived) {
+		t.Errorf("g.Wait() = %v; want it to match both %v and ErrSignalReceived", err, errDoom)
+	}
+}
+
+func TestStopClosedOnce(t *testing.T) {
+	ignoreSignal(t, syscall.SIGHUP)
+
+	g, ctx, stop := errgroup.WithSignalHandler(context.Background(), syscall.SIGHUP)
+	g.SetExitFunc(func(int) {})
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	waited := make(chan struct{})
+	go func() {
+		g.Wait()
+		close(waited)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	sendSignal(t, syscall.SIGHUP)
+	sendSignal(t, syscall.SIGHUP)
+
+	select {
+	case <-stop:
+	case <-time.After(time.Second):
+		t.Errorf("stop was not closed after two signals")
+	}
+
+	<-waited
+}
+
+func TestStopClosedByCaller(t *testing.T) {
+	g, _, stop := errgroup.WithSignalHandler(context.Background())
+	close(stop)
+
+	g.Go(func() error { return nil })
+	g.Wait() // Must not panic.
+}
+
+func TestStopContract(t *testing.T) {
+	for _, keepOpen := range []bool{false, true} {
+		for _, signaled := range []bool{false, true} {
+			g, ctx, stop := errgroup.WithSignalHandler(context.Background())
+			if keepOpen {
+				g.KeepStopOpen()
+			}
+
+			sigs := make(chan os.Signal, 1)
+			g.SetSignalSource(sigs)
+
+			g.Go(func() error {
+				if signaled {
+					<-ctx.Done()
+				}
+				return nil
+			})
+
+			if signaled {
+				sigs <- syscall.SIGTERM
+			}
+
+			g.Wait()
+			g.Wait() // Must not close stop again.
+
+			closed := false
+			select {
+			case <-stop:
+				closed = true
+			default:
+			}
+
+			if want := signaled || !keepOpen; closed != want {
+				t.Errorf("keepOpen=%t, signaled=%t: stop closed = %t; want %t", keepOpen, signaled, closed, want)
+			}
+		}
+	}
+}
+
+func TestSetSignalSource(t *testing.T) {
+	g, ctx, stop := errgroup.WithSignalHandler(context.Background())
+
+	sigs := make(chan os.Signal, 1)
+	g.SetSignalSource(sigs)
+
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	sigs <- syscall.SIGTERM
+
+	err := g.Wait()
+	if !errors.Is(err, errgroup.ErrSignalReceived) {
+		t.Errorf("g.Wait() = %v; want it to match ErrSignalReceived", err)
+	}
+
+	select {
+	case <-stop:
+	default:
+		t.Errorf("stop was not closed")
+	}
+}
+
+func TestSetSignalSourceNil(t *testing.T) {
+	g, _ := errgroup.WithContext(context.Background())
+	g.SetSignalSource(nil)
+
+	// Spin long enough for the runtime to preempt the goroutine with SIGURG,
+	// which must not reach the group.
+	g.Go(func() error {
+		for deadline := time.Now().Add(100 * time.Millisecond); time.Now().Before(deadline); {
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("g.Wait() = %v; want nil", err)
+	}
+	if sig, ok := g.CaughtSignal(); ok {
+		t.Errorf("g.CaughtSignal() = %v, true; want no signal", sig)
+	}
+}
+
+func TestWithSignals(t *testing.T) {
+	g, ctx := errgroup.WithSignals(context.Background(), syscall.SIGHUP)
+
+	sigs := make(chan os.Signal, 1)
+	g.SetSignalSource(sigs)
+
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	sigs <- syscall.SIGHUP
+
+	if err := g.Wait(); !errors.Is(err, errgroup.ErrSignalReceived) {
+		t.Errorf("g.Wait() = %v; want it to match ErrSignalReceived", err)
+	}
+
+	var sigErr *errgroup.SignalError
+	if cause := context.Cause(ctx); !errors.As(cause, &sigErr) || sigErr.Signal() != syscall.SIGHUP {
+		t.Errorf("context.Cause(ctx) = %v; want a *SignalError for %v", cause, syscall.SIGHUP)
+	}
+}
+
+func TestFinallyCtxSignal(t *testing.T) {
+	g, ctx := errgroup.WithSignals(context.Background())
+
+	sigs := make(chan os.Signal, 1)
+	g.SetSignalSource(sigs)
+
+	causes := make(chan error, 1)
+	g.FinallyCtx(func(ctx context.Context) error {
+		causes <- context.Cause(ctx)
+		return nil
+	})
+
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	sigs <- syscall.SIGTERM
+	g.Wait()
+
+	var sigErr *errgroup.SignalError
+	if cause := <-causes; !errors.As(cause, &sigErr) || sigErr.Signal() != syscall.SIGTERM {
+		t.Errorf("context.Cause(ctx) in FinallyCtx = %v; want a *SignalError for %v", cause, syscall.SIGTERM)
+	}
+}
+
+func TestSucceededSignal(t *testing.T) {
+	g, ctx, _ := errgroup.WithSignalHandler(context.Background())
+
+	sigs := make(chan os.Signal, 1)
+	g.SetSignalSource(sigs)
+
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	sigs <- syscall.SIGTERM
+	g.Wait()
+
+	if g.Succeeded() {
+		t.Errorf("g.Succeeded() after a caught signal = true; want false")
+	}
+}
+
+func TestSignalCause(t *testing.T) {
+	g, ctx, _ := errgroup.WithSignalHandler(context.Background())
+
+	sigs := make(chan os.Signal, 1)
+	g.SetSignalSource(sigs)
+
+	var cause error
+	g.Go(func() error {
+		<-ctx.Done()
+		cause = context.Cause(ctx)
+		return nil
+	})
+
+	sigs <- syscall.SIGTERM
+	g.Wait()
+
+	var sigErr *errgroup.SignalError
+	if !errors.As(cause, &sigErr) {
+		t.Fatalf("context.Cause(ctx) = %v; want a *SignalError", cause)
+	}
+	if sigErr.Signal() != syscall.SIGTERM {
+		t.Errorf("sigErr.Signal() = %v; want %v", sigErr.Signal(), syscall.SIGTERM)
+	}
+}
+
+func TestSignalErrorString(t *testing.T) {
+	cases := []struct {
+		sig  os.Signal
+		want string
+	}{
+		{sig: syscall.SIGTERM, want: "received signal: terminated (SIGTERM)"},
+		{sig: syscall.SIGINT, want: "received signal: interrupt (SIGINT)"},
+	}
+
+	for _, tc := range cases {
+		g, ctx, _ := errgroup.WithSignalHandler(context.Background())
+
+		sigs := make(chan os.Signal, 1)
+		g.SetSignalSource(sigs)
+
+		g.Go(func() error {
+			<-ctx.Done()
+			return nil
+		})
+
+		sigs <- tc.sig
+		err := g.Wait()
+
+		if !errors.Is(err, errgroup.ErrSignalReceived) {
+			t.Errorf("%v: g.Wait() = %v; want it to match ErrSignalReceived", tc.sig, err)
+		}
+		if got := err.Error(); got != tc.want {
+			t.Errorf("%v: g.Wait().Error() = %q; want %q", tc.sig, got, tc.want)
+		}
+	}
+}
+
+func TestSignalFinallyError(t *testing.T) {
+	errCleanup := errors.New("group_test: cleanup failed")
+
+	g, ctx, _ := errgroup.WithSignalHandler(context.Background())
+
+	sigs := make(chan os.Signal, 1)
+	g.SetSignalSource(sigs)
+
+	g.Finally(func() error { return errCleanup })
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	sigs <- syscall.SIGTERM
+	err := g.Wait()
+
+	if !errors.Is(err, errgroup.ErrSignalReceived) {
+		t.Errorf("g.Wait() = %v; want it to match ErrSignalReceived", err)
+	}
+	var sigErr *errgroup.SignalError
+	if !errors.As(err, &sigErr) || sigErr.Signal() != syscall.SIGTERM {
+		t.Errorf("g.Wait() = %v; want a *SignalError for %v", err, syscall.SIGTERM)
+	}
+	if !errors.Is(err, errCleanup) {
+		t.Errorf("g.Wait() = %v; want it to match %v", err, errCleanup)
+	}
+}
+
+func TestCaughtSignal(t *testing.T) {
+	g, ctx, _ := errgroup.WithSignalHandler(context.Background())
+
+	sigs := make(chan os.Signal, 1)
+	g.SetSignalSource(sigs)
+
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	if sig, ok := g.CaughtSignal(); ok {
+		t.Errorf("g.CaughtSignal() = %v, true before a signal; want nil, false", sig)
+	}
+
+	sigs <- syscall.SIGINT
+	g.Wait()
+
+	if sig, ok := g.CaughtSignal(); sig != syscall.SIGINT || !ok {
+		t.Errorf("g.CaughtSignal() = %v, %t; want %v, true", sig, ok, syscall.SIGINT)
+	}
+}
+
+func TestSetSignalHandler(t *testing.T) {
+	g, ctx, stop := errgroup.WithSignalHandler(context.Background(), syscall.SIGHUP, syscall.SIGTERM)
+
+	sigs := make(chan os.Signal, 1)
+	g.SetSignalSource(sigs)
+
+	seen := make(chan os.Signal, 4)
+	g.SetSignalHandler(func(sig os.Signal) bool {
+		seen <- sig
+		return sig != syscall.SIGHUP
+	})
+
+	exited := false
+	g.SetExitFunc(func(int) { exited = true })
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-ctx.Done()
+		<-release
+		return nil
+	})
+
+	waited := make(chan error, 1)
+	go func() { waited <- g.Wait() }()
+
+	sigs <- syscall.SIGHUP
+	if sig := <-seen; sig != syscall.SIGHUP {
+		t.Fatalf("handler saw %v; want %v", sig, syscall.SIGHUP)
+	}
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("SIGHUP canceled the group")
+	case <-stop:
+		t.Fatalf("SIGHUP closed stop")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	sigs <- syscall.SIGTERM
+	<-seen
+
+	sigs <- syscall.SIGHUP
+	<-seen
+	close(release)
+
+	err := <-waited
+	var sigErr *errgroup.SignalError
+	if !errors.As(err, &sigErr) || sigErr.Signal() != syscall.SIGTERM {
+		t.Errorf("g.Wait() = %v; want a *SignalError for %v", err, syscall.SIGTERM)
+	}
+
+	select {
+	case <-stop:
+	default:
+		t.Errorf("stop was not closed")
+	}
+
+	if exited {
+		t.Errorf("a SIGHUP ignored by the handler after shutdown exited the program")
+	}
+}
+
+func TestForwardSignals(t *testing.T) {
+	g, _, stop := errgroup.WithSignalHandler(context.Background())
+
+	sigs := make(chan os.Signal, 4)
+	g.SetSignalSource(sigs)
+
+	forwarded := make(chan os.Signal)
+	g.ForwardSignals(forwarded)
+
+	exited := false
+	g.SetExitFunc(func(int) { exited = true })
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release // Ignores cancellation.
+		return nil
+	})
+
+	want := []os.Signal{syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM}
+	sigs <- syscall.SIGTERM
+	for _, sig := range want {
+		sigs <- sig
+	}
+
+	waited := make(chan struct{})
+	go func() {
+		g.Wait()
+		close(waited)
+	}()
+
+	<-stop
+	for _, sig := range want {
+		select {
+		case got := <-forwarded:
+			if got != sig {
+				t.Errorf("forwarded %v; want %v", got, sig)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%v was not forwarded", sig)
+		}
+	}
+
+	close(release)
+	<-waited
+
+	if exited {
+		t.Errorf("exit was called in forwarding mode")
+	}
+}
+
+func TestReraiseSignals(t *testing.T) {
+	for _, reraise := range []bool{false, true} {
+		g, ctx, _ := errgroup.WithSignalHandler(context.Background())
+		g.ReraiseSignals(reraise)
+
+		sigs := make(chan os.Signal, 2)
+		g.SetSignalSource(sigs)
+
+		terminated := make(chan string, 1)
+		g.SetExitFunc(func(code int) { terminated <- fmt.Sprintf("exit %d", code) })
+		errgroup.SetRaise(g, func(sig os.Signal) error {
+			terminated <- fmt.Sprintf("raise %v", sig)
+			return nil
+		})
+
+		release := make(chan struct{})
+		g.Go(func() error {
+			<-ctx.Done()
+			<-release // Ignores cancellation.
+			return nil
+		})
+
+		sigs <- syscall.SIGTERM
+		sigs <- syscall.SIGINT
+
+		waited := make(chan struct{})
+		go func() {
+			g.Wait()
+			close(waited)
+		}()
+
+		want := "exit 0"
+		if reraise {
+			want = fmt.Sprintf("raise %v", syscall.SIGINT)
+		}
+
+		select {
+		case got := <-terminated:
+			if got != want {
+				t.Errorf("ReraiseSignals(%t): handler did %q; want %q", reraise, got, want)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("ReraiseSignals(%t): handler did not terminate", reraise)
+		}
+
+		close(release)
+		<-waited
+	}
+}
+
+// ignoreSignal keeps sig from terminating the test binary when no group is
+// catching it.
+func ignoreSignal(t *testing.T, sig os.Signal) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sig)
+	t.Cleanup(func() { signal.Stop(c) })
+}
+
+func TestErrSignalReceived(t *testing.T) {
+	ignoreSignal(t, os.Interrupt)
+
+	errDoom := errors.New("group_test: doomed")
+
+	for _, taskErr := range []error{nil, errDoom} {
+		g, ctx, _ := errgroup.WithSignalHandler(context.Background())
+
+		var cause error
+		g.OnCancel(func(err error) { cause = err })
+
+		g.Go(func() error {
+			<-ctx.Done()
+			return taskErr
+		})
+
+		waited := make(chan error)
+		go func() { waited <- g.Wait() }()
+
+		time.Sleep(100 * time.Millisecond)
+		sendSignal(t, os.Interrupt)
+
+		err := <-waited
+		if !errors.Is(err, errgroup.ErrSignalReceived) {
+			t.Errorf("g.Wait() = %v; want it to match ErrSignalReceived", err)
+		}
+
+		var sigErr *errgroup.SignalError
+		if !errors.As(err, &sigErr) {
+			t.Fatalf("g.Wait() = %v; want a *SignalError", err)
+		}
+		if sigErr.Signal() != os.Interrupt {
+			t.Errorf("sigErr.Signal() = %v; want %v", sigErr.Signal(), os.Interrupt)
+		}
+		if cause != sigErr {
+			t.Errorf("OnCancel cause = %v; want %v", cause, sigErr)
+		}
+		if cause := context.Cause(ctx); !errors.Is(cause, errgroup.ErrSignalReceived) {
+			t.Errorf("context.Cause(ctx) = %v; want it to match ErrSignalReceived", cause)
+		}
+
+		if taskErr != nil && !errors.Is(err, taskErr) {
+			t.Errorf("g.Wait() = %v; want it to match %v", err, taskErr)
+		}
+	}
+}
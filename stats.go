@@ -0,0 +1,131 @@
+package errgroup
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// GroupStats summarizes the functions run by a Group.
+type GroupStats struct {
+	// Started is the number of functions that have started running.
+	Started int
+
+	// Succeeded is the number of functions that returned nil.
+	Succeeded int
+
+	// Failed is the number of functions that returned an error.
+	Failed int
+
+	// TotalDuration is the wall-clock time from the start of the first
+	// function to the return of the last one.
+	TotalDuration time.Duration
+
+	// MaxDuration is how long the slowest function ran.
+	MaxDuration time.Duration
+}
+
+// stats accumulates GroupStats as functions start and return.
+type stats struct {
+	started     atomic.Int64
+	succeeded   atomic.Int64
+	failed      atomic.Int64
+	maxDuration atomic.Int64
+
+	// begin and end are when the first function was passed to Go and when
+	// Wait last returned.
+	begin atomic.Pointer[time.Time]
+	end   atomic.Pointer[time.Time]
+
+	// keepTasks is set by EnableTaskReports; tasks is only appended to then.
+	keepTasks atomic.Bool
+
+	mu    sync.Mutex
+	first time.Time
+	last  time.Time
+	tasks []TaskReport
+}
+
+func (s *stats) taskStarted(now time.Time) {
+	s.started.Add(1)
+
+	s.mu.Lock()
+	if s.first.IsZero() {
+		s.first = now
+	}
+	s.mu.Unlock()
+}
+
+func (s *stats) taskFinished(idx int64, name string, err error, now time.Time, dur time.Duration) {
+	if err != nil {
+		s.failed.Add(1)
+	} else {
+		s.succeeded.Add(1)
+	}
+
+	for {
+		cur := s.maxDuration.Load()
+		if int64(dur) <= cur || s.maxDuration.CompareAndSwap(cur, int64(dur)) {
+			break
+		}
+	}
+
+	keep := s.keepTasks.Load()
+	var task TaskReport
+	if keep {
+		task = TaskReport{Index: idx, Name: name, Duration: dur}
+		if err != nil {
+			task.Error = err.Error()
+		}
+	}
+
+	s.mu.Lock()
+	if now.After(s.last) {
+		s.last = now
+	}
+	if keep {
+		s.tasks = append(s.tasks, task)
+	}
+	s.mu.Unlock()
+}
+
+func (s *stats) snapshot() GroupStats {
+	s.mu.Lock()
+	total := s.last.Sub(s.first)
+	s.mu.Unlock()
+
+	if total < 0 {
+		total = 0
+	}
+
+	return GroupStats{
+		Started:       int(s.started.Load()),
+		Succeeded:     int(s.succeeded.Load()),
+		Failed:        int(s.failed.Load()),
+		TotalDuration: total,
+		MaxDuration:   time.Duration(s.maxDuration.Load()),
+	}
+}
+
+// Stats returns a summary of the functions run by the group so far. It is
+// meant to be called after Wait.
+func (g *Group) Stats() GroupStats {
+	return g.stats.snapshot()
+}
+
+// Elapsed returns the wall-clock time from the first call to Go, or one of its
+// variants, to the return of Wait, or to now if Wait has not returned since.
+// It is zero for a group to which no function was passed.
+func (g *Group) Elapsed() time.Duration {
+	begin := g.stats.begin.Load()
+	if begin == nil {
+		return 0
+	}
+
+	end := g.stats.end.Load()
+	if end == nil || end.Before(*begin) {
+		return g.clock().Now().Sub(*begin)
+	}
+
+	return end.Sub(*begin)
+}
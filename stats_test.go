@@ -0,0 +1,100 @@
+package errgroup_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestStats(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+
+	g := new(errgroup.Group)
+	if got := g.Stats(); got != (errgroup.GroupStats{}) {
+		t.Errorf("g.Stats() = %+v before Go; want zero", got)
+	}
+
+	g.Go(func() error { return nil })
+	g.Go(func() error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	g.Go(func() error { return errDoom })
+	g.Wait()
+
+	got := g.Stats()
+	if got.Started != 3 {
+		t.Errorf("Started = %d; want 3", got.Started)
+	}
+	if got.Succeeded != 2 {
+		t.Errorf("Succeeded = %d; want 2", got.Succeeded)
+	}
+	if got.Failed != 1 {
+		t.Errorf("Failed = %d; want 1", got.Failed)
+	}
+	if got.MaxDuration < 20*time.Millisecond {
+		t.Errorf("MaxDuration = %v; want ≥ 20ms", got.MaxDuration)
+	}
+	if got.TotalDuration < got.MaxDuration {
+		t.Errorf("TotalDuration = %v; want ≥ MaxDuration %v", got.TotalDuration, got.MaxDuration)
+	}
+}
+
+func TestElapsed(t *testing.T) {
+	g := new(errgroup.Group)
+	if d := g.Elapsed(); d != 0 {
+		t.Errorf("g.Elapsed() of an unused Group = %v; want 0", d)
+	}
+
+	const sleep = 50 * time.Millisecond
+	for i := 0; i < 3; i++ {
+		g.Go(func() error {
+			time.Sleep(sleep)
+			return nil
+		})
+	}
+
+	if d := g.Elapsed(); d <= 0 || d >= sleep {
+		t.Errorf("g.Elapsed() while running = %v; want it in (0, %v)", d, sleep)
+	}
+
+	g.Wait()
+
+	d := g.Elapsed()
+	if d < sleep || d > 10*sleep {
+		t.Errorf("g.Elapsed() = %v; want it in [%v, %v]", d, sleep, 10*sleep)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if again := g.Elapsed(); again != d {
+		t.Errorf("g.Elapsed() after Wait changed from %v to %v", d, again)
+	}
+}
+
+func TestElapsedFakeClock(t *testing.T) {
+	clock := errgroup.NewFakeClock()
+	g := new(errgroup.Group)
+	errgroup.SetClock(g, clock)
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release
+		return nil
+	})
+
+	clock.Advance(time.Minute)
+	if d := g.Elapsed(); d != time.Minute {
+		t.Errorf("g.Elapsed() while running = %v; want %v", d, time.Minute)
+	}
+
+	close(release)
+	clock.Advance(time.Second)
+	g.Wait()
+	clock.Advance(time.Hour)
+
+	if want := time.Minute + time.Second; g.Elapsed() != want {
+		t.Errorf("g.Elapsed() = %v; want %v", g.Elapsed(), want)
+	}
+}
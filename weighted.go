@@ -0,0 +1,274 @@
+package errgroup
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrExceedsCapacity is recorded for a function whose weight exceeds the
+// group's total capacity, which could never be satisfied.
+var ErrExceedsCapacity = errors.New("errgroup: weight exceeds capacity")
+
+// ErrExceedsBudget is recorded for a function whose size exceeds the group's
+// total memory budget, which could never be satisfied.
+var ErrExceedsBudget = errors.New("errgroup: size exceeds memory budget")
+
+// SetCapacity sets the total weight of the functions passed to GoWeighted that
+// may run at once. A zero or negative total removes the capacity, making
+// GoWeighted behave like Go.
+//
+// The capacity must not be modified while any goroutines in the group are
+// active.
+func (g *Group) SetCapacity(total int64) {
+	if total <= 0 {
+		g.weighted = nil
+		return
+	}
+
+	g.weighted = newWeighted(total)
+}
+
+// GoWeighted calls the given function in a new goroutine, as Go does, once
+// weight units of the group's capacity are free, blocking until then; see
+// SetCapacity. The units are released when the function returns. If weight
+// exceeds the capacity, the function is not called and an error matching
+// ErrExceedsCapacity is recorded instead. If the group's Context is canceled
+// while GoWeighted is blocked, it returns without calling the function and
+// records the Context's error.
+func (g *Group) GoWeighted(weight int64, f func() error) {
+	if f == nil {
+		panicNilFunc("GoWeighted")
+	}
+
+	s := g.weighted
+	if s != nil && weight > s.size {
+		g.record(fmt.Errorf("%w: weight %d, capacity %d", ErrExceedsCapacity, weight, s.size), nil)
+		return
+	}
+
+	g.goWeighted(s, weight, f)
+}
+
+// SetMemoryBudget sets the total estimated memory, in bytes, of the functions
+// passed to GoSized that may run at once. A zero or negative total removes the
+// budget, making GoSized behave like Go.
+//
+// The budget must not be modified while any goroutines in the group are
+// active.
+func (g *Group) SetMemoryBudget(total int64) {
+	if total <= 0 {
+		g.budget = nil
+		return
+	}
+
+	g.budget = newWeighted(total)
+}
+
+// GoSized calls the given function in a new goroutine, as Go does, once bytes
+// of the group's memory budget are free, blocking until then; see
+// SetMemoryBudget. bytes is the caller's estimate of the memory the function
+// uses, released when it returns. If bytes exceeds the budget, the function is
+// not called and an error matching ErrExceedsBudget is recorded instead. If the
+// group's Context is canceled while GoSized is blocked, it returns without
+// calling the function and records the Context's error.
+func (g *Group) GoSized(bytes int64, f func() error) {
+	if f == nil {
+		panicNilFunc("GoSized")
+	}
+
+	s := g.budget
+	if s != nil && bytes > s.size {
+		g.record(fmt.Errorf("%w: %d bytes, budget %d", ErrExceedsBudget, bytes, s.size), nil)
+		return
+	}
+
+	g.goWeighted(s, bytes, f)
+}
+
+// goWeighted calls f in a new goroutine once weight units of s are free, or as
+// Go does if s is nil. weight must not exceed the size of s.
+func (g *Group) goWeighted(s *weighted, weight int64, f func() error) {
+	if s == nil {
+		g.Go(f)
+		return
+	}
+
+	if g.closed() {
+		return
+	}
+
+	idx := g.nextIndex()
+
+	ctx := g.context()
+	if err := s.Acquire(ctx, weight); err != nil {
+		g.record(err, nil)
+		return
+	}
+
+	if err := g.acquire(); err != nil {
+		s.Release(weight)
+		return
+	}
+
+	if err := g.throttle(); err != nil {
+		s.Release(weight)
+		if g.sem != nil {
+			g.sem.Release(1)
+		}
+		return
+	}
+
+	g.start(idx, "", f, func() error {
+		defer s.Release(weight)
+		return f()
+	}, nil)
+}
+
+// weighted is a weighted semaphore, after golang.org/x/sync/semaphore. Waiters
+// are served in FIFO order.
+type weighted struct {
+	size    int64
+	cur     int64
+	mu      sync.Mutex
+	waiters list.List
+}
+
+type waiter struct {
+	n     int64
+	ready chan<- struct{} // Closed when the semaphore is acquired.
+}
+
+func newWeighted(n int64) *weighted {
+	return &weighted{size: n}
+}
+
+// Acquire acquires the semaphore with a weight of n, blocking until resources
+// are available or ctx is done. On failure, it returns ctx.Err() and leaves
+// the semaphore unchanged.
+func (s *weighted) Acquire(ctx context.Context, n int64) error {
+	done := ctx.Done()
+
+	s.mu.Lock()
+	select {
+	case <-done:
+		s.mu.Unlock()
+		return ctx.Err()
+	default:
+	}
+
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	elem := s.waiters.PushBack(waiter{n: n, ready: ready})
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+		s.mu.Lock()
+		select {
+		case <-ready:
+			// Acquired the semaphore after we were canceled; give it back.
+			s.cur -= n
+			s.notifyWaiters()
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// If we're at the front and there are extra tokens left, notify
+			// the other waiters.
+			if isFront && s.size > s.cur {
+				s.notifyWaiters()
+			}
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+
+	case <-ready:
+		// Acquired the semaphore. Check that ctx isn't already done, so that
+		// cancellation is reported consistently.
+		select {
+		case <-done:
+			s.Release(n)
+			return ctx.Err()
+		default:
+		}
+		return nil
+	}
+}
+
+// TryAcquire acquires the semaphore with a weight of n without blocking. On
+// success, it returns true. On failure, it returns false and leaves the
+// semaphore unchanged.
+func (s *weighted) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size-s.cur < n || s.waiters.Len() != 0 {
+		return false
+	}
+
+	s.cur += n
+
+	return true
+}
+
+// Held returns the weight currently held. It is safe to call on a nil
+// semaphore, which holds nothing.
+func (s *weighted) Held() int64 {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cur
+}
+
+// Resize changes the total weight of the semaphore to n. Waiters that now fit
+// acquire it at once; if n is below the weight held, no one acquires it until
+// enough is released.
+func (s *weighted) Resize(n int64) {
+	s.mu.Lock()
+	s.size = n
+	s.notifyWaiters()
+	s.mu.Unlock()
+}
+
+// Release releases the semaphore with a weight of n.
+func (s *weighted) Release(n int64) {
+	s.mu.Lock()
+	s.cur -= n
+	if s.cur < 0 {
+		s.mu.Unlock()
+		panic("errgroup: released more than held")
+	}
+	s.notifyWaiters()
+	s.mu.Unlock()
+}
+
+func (s *weighted) notifyWaiters() {
+	for {
+		next := s.waiters.Front()
+		if next == nil {
+			break // No more waiters blocked.
+		}
+
+		w := next.Value.(waiter)
+		if s.size-s.cur < w.n {
+			// Not enough tokens for the next waiter. Stop here rather than
+			// letting smaller waiters barge past it and starve it.
+			break
+		}
+
+		s.cur += w.n
+		s.waiters.Remove(next)
+		close(w.ready)
+	}
+}
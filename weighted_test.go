@@ -0,0 +1,141 @@
+package errgroup_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rdeusser/errgroup"
+)
+
+func TestGoWeighted(t *testing.T) {
+	const capacity = 4
+
+	g := new(errgroup.Group)
+	g.SetCapacity(capacity)
+
+	var inFlight int64
+	for i := 0; i < 100; i++ {
+		weight := int64(1)
+		if i%3 == 0 {
+			weight = 3
+		}
+
+		g.GoWeighted(weight, func() error {
+			n := atomic.AddInt64(&inFlight, weight)
+			defer atomic.AddInt64(&inFlight, -weight)
+			if n > capacity {
+				return fmt.Errorf("saw weight %d in flight; want ≤ %d", n, capacity)
+			}
+			time.Sleep(10 * time.Microsecond)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGoWeightedExceedsCapacity(t *testing.T) {
+	g := new(errgroup.Group)
+	g.SetCapacity(2)
+
+	ran := false
+	g.GoWeighted(3, func() error {
+		ran = true
+		return nil
+	})
+
+	if err := g.Wait(); !errors.Is(err, errgroup.ErrExceedsCapacity) {
+		t.Errorf("g.Wait() = %v; want it to match ErrExceedsCapacity", err)
+	}
+	if ran {
+		t.Errorf("oversized task ran")
+	}
+}
+
+func TestGoWeightedCanceled(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	g, _ := errgroup.WithContext(parent)
+	g.SetCapacity(1)
+
+	release := make(chan struct{})
+	g.GoWeighted(1, func() error {
+		<-release
+		return nil
+	})
+
+	ran := false
+	returned := make(chan struct{})
+	go func() {
+		g.GoWeighted(1, func() error {
+			ran = true
+			return nil
+		})
+		close(returned)
+	}()
+
+	cancel()
+	<-returned
+	close(release)
+
+	if err := g.Wait(); err != context.Canceled {
+		t.Errorf("g.Wait() = %v; want %v", err, context.Canceled)
+	}
+	if ran {
+		t.Errorf("blocked task ran after the group was canceled")
+	}
+}
+
+func TestGoSized(t *testing.T) {
+	const budget = 1 << 20
+
+	g := new(errgroup.Group)
+	g.SetMemoryBudget(budget)
+
+	var inUse int64
+	for i := 0; i < 100; i++ {
+		size := int64(budget / 8)
+		if i%4 == 0 {
+			size = budget / 2
+		}
+
+		g.GoSized(size, func() error {
+			n := atomic.AddInt64(&inUse, size)
+			defer atomic.AddInt64(&inUse, -size)
+			if n > budget {
+				return fmt.Errorf("saw %d bytes in use; want ≤ %d", n, budget)
+			}
+			time.Sleep(10 * time.Microsecond)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGoSizedExceedsBudget(t *testing.T) {
+	g := new(errgroup.Group)
+	g.SetMemoryBudget(1024)
+
+	ran := false
+	g.GoSized(2048, func() error {
+		ran = true
+		return nil
+	})
+
+	if err := g.Wait(); !errors.Is(err, errgroup.ErrExceedsBudget) {
+		t.Errorf("g.Wait() = %v; want it to match ErrExceedsBudget", err)
+	}
+	if ran {
+		t.Errorf("oversized task ran")
+	}
+}